@@ -0,0 +1,88 @@
+package rushgo
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+)
+
+// WithAccounting calls report with the bytes sent and received for every
+// attempt a request makes, along with the target host, e.g. for metering or
+// cost attribution. Bodies are counted exactly as they pass through the
+// client; received bodies are counted after transparent decompression. Header
+// sizes are approximate: they are estimated from the HTTP/1.1 wire format, so
+// HTTP/2 and HTTP/3 header compression is not reflected. report runs once the
+// response body is closed, or right away if the attempt fails, and is not
+// called for responses replayed from a cassette.
+func (rg *RushGo) WithAccounting(report func(bytesSent, bytesReceived int64, host string)) *RushGo {
+	rg.accounting = report
+	return rg
+}
+
+// account wraps send to count the bytes of each attempt for WithAccounting
+func (rg *RushGo) account(send func(*http.Request) (*http.Response, error)) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		sent := new(int64)
+		*sent = requestHeaderSize(req)
+		if req.Body != nil && req.Body != http.NoBody {
+			req = req.WithContext(req.Context())
+			req.Body = &countingReadCloser{countingReader{r: req.Body, n: sent}, req.Body}
+		}
+		host := req.URL.Host
+
+		resp, err := send(req)
+		if err != nil {
+			rg.accounting(atomic.LoadInt64(sent), 0, host)
+			return nil, err
+		}
+
+		received := new(int64)
+		*received = responseHeaderSize(resp)
+		resp.Body = &accountedBody{
+			countingReadCloser: countingReadCloser{countingReader{r: resp.Body, n: received}, resp.Body},
+			report: func() {
+				rg.accounting(atomic.LoadInt64(sent), atomic.LoadInt64(received), host)
+			},
+		}
+		return resp, nil
+	}
+}
+
+// accountedBody reports the byte counts when it is first closed
+type accountedBody struct {
+	countingReadCloser
+	once   sync.Once
+	report func()
+}
+
+func (b *accountedBody) Close() error {
+	err := b.countingReadCloser.Close()
+	b.once.Do(b.report)
+	return err
+}
+
+// requestHeaderSize estimates the request line and headers as sent over HTTP/1.1
+func requestHeaderSize(req *http.Request) int64 {
+	// "METHOD URI HTTP/1.1\r\n" and "Host: host\r\n"
+	size := len(req.Method) + 1 + len(req.URL.RequestURI()) + len(" HTTP/1.1\r\n")
+	size += len("Host: \r\n") + len(req.URL.Host)
+	return int64(size + headerSize(req.Header))
+}
+
+// responseHeaderSize estimates the status line and headers as received over HTTP/1.1
+func responseHeaderSize(resp *http.Response) int64 {
+	// "HTTP/1.1 200 OK\r\n"
+	size := len("HTTP/1.1 ") + len(resp.Status) + len("\r\n")
+	return int64(size + headerSize(resp.Header))
+}
+
+// headerSize counts "Key: value\r\n" for every value plus the blank line
+func headerSize(header http.Header) int {
+	size := len("\r\n")
+	for key, values := range header {
+		for _, value := range values {
+			size += len(key) + len(": ") + len(value) + len("\r\n")
+		}
+	}
+	return size
+}
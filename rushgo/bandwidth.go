@@ -0,0 +1,102 @@
+package rushgo
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// bandwidthLimiter paces all body transfers of a client to a shared byte rate
+type bandwidthLimiter struct {
+	mu   sync.Mutex
+	rate int64     // bytes per second, zero for unlimited
+	next time.Time // when the bytes reserved so far will have been "paid for"
+}
+
+// chunk returns the largest read worth doing in one go at the current rate,
+// so throttled transfers stay smooth
+func (l *bandwidthLimiter) chunk(n int) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate <= 0 {
+		return n
+	}
+	limit := int(l.rate / 10)
+	if limit < 1024 {
+		limit = 1024
+	}
+	if n > limit {
+		return limit
+	}
+	return n
+}
+
+// wait blocks long enough that n more bytes keep the transfer within the rate
+func (l *bandwidthLimiter) wait(ctx context.Context, n int) error {
+	l.mu.Lock()
+	if l.rate <= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(float64(n) / float64(l.rate) * float64(time.Second)))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// throttledReadCloser reads through a bandwidthLimiter
+type throttledReadCloser struct {
+	io.ReadCloser
+	limiter *bandwidthLimiter
+	ctx     context.Context
+}
+
+func (t *throttledReadCloser) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	n, err := t.ReadCloser.Read(p[:t.limiter.chunk(len(p))])
+	if n > 0 {
+		if waitErr := t.limiter.wait(t.ctx, n); waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+	return n, err
+}
+
+// WithBandwidthLimit caps the combined rate at which the client reads response
+// bodies and writes request bodies to bytesPerSec, so large transfers do not
+// saturate a shared link. Zero removes the limit. It can be changed at any
+// time with SetBandwidthLimit, including while transfers are running.
+func (rg *RushGo) WithBandwidthLimit(bytesPerSec int64) *RushGo {
+	rg.SetBandwidthLimit(bytesPerSec)
+	return rg
+}
+
+// SetBandwidthLimit changes the bandwidth limit, see WithBandwidthLimit
+func (rg *RushGo) SetBandwidthLimit(bytesPerSec int64) {
+	rg.bandwidth.mu.Lock()
+	rg.bandwidth.rate = bytesPerSec
+	rg.bandwidth.mu.Unlock()
+}
+
+// throttle wraps body so it is read at the client's bandwidth limit
+func (rg *RushGo) throttle(ctx context.Context, body io.ReadCloser) io.ReadCloser {
+	return &throttledReadCloser{ReadCloser: body, limiter: rg.bandwidth, ctx: ctx}
+}
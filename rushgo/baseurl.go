@@ -0,0 +1,135 @@
+package rushgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// failoverCooldown is how long a base URL that failed is tried after the healthy ones
+const failoverCooldown = 30 * time.Second
+
+// baseURLPool holds the base URLs that relative request paths are resolved against
+type baseURLPool struct {
+	urls []string
+
+	mu       sync.Mutex
+	failedAt map[string]time.Time
+	statuses map[int]bool // response statuses that trigger failover
+}
+
+// WithBaseURLs resolves relative request paths such as "/users" against the
+// given base URLs, trying them in order until one succeeds. A base fails over
+// to the next on a connection error or a status set with WithFailoverStatus.
+// Bases that failed in the last 30 seconds are tried after the healthy ones.
+// Each base is tried once per attempt; a reauth on 401 happens within a single
+// base. With WithRetry, every retry runs the whole cycle again, and the order
+// is worked out anew for it: unless the backoff outlasts the cooldown, bases
+// that failed during the previous cycle move behind any base that answered,
+// e.g. with a retryable status that is not a failover status. If every base
+// failed, the retry walks them in the configured order again.
+func (rg *RushGo) WithBaseURLs(urls []string) *RushGo {
+	statuses := map[int]bool{}
+	if rg.baseURLs != nil {
+		statuses = rg.baseURLs.statuses
+	}
+	rg.baseURLs = &baseURLPool{
+		urls:     append([]string(nil), urls...),
+		failedAt: make(map[string]time.Time),
+		statuses: statuses,
+	}
+	return rg
+}
+
+// WithFailoverStatus sets response status codes, e.g. 502 and 503, that make a
+// request fail over to the next base URL set with WithBaseURLs
+func (rg *RushGo) WithFailoverStatus(codes ...int) *RushGo {
+	if rg.baseURLs == nil {
+		rg.WithBaseURLs(nil)
+	}
+	for _, code := range codes {
+		rg.baseURLs.statuses[code] = true
+	}
+	return rg
+}
+
+// isRelativeURL reports whether rawURL has neither a scheme nor a host
+func isRelativeURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && u.Scheme == "" && u.Host == ""
+}
+
+// candidates returns path joined to each base, healthy bases first
+func (p *baseURLPool) candidates(path string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var healthy, failed []string
+	now := time.Now()
+	for _, base := range p.urls {
+		full := strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+		if failedAt, ok := p.failedAt[base]; ok && now.Sub(failedAt) < failoverCooldown {
+			failed = append(failed, full)
+		} else {
+			healthy = append(healthy, full)
+		}
+	}
+	return append(healthy, failed...)
+}
+
+// mark records whether the base that full was built from just succeeded
+func (p *baseURLPool) mark(full string, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, base := range p.urls {
+		if strings.HasPrefix(full, strings.TrimRight(base, "/")+"/") {
+			if ok {
+				delete(p.failedAt, base)
+			} else {
+				p.failedAt[base] = time.Now()
+			}
+			return
+		}
+	}
+}
+
+// shouldFailover reports whether the outcome of an attempt should move on to the next base
+func (p *baseURLPool) shouldFailover(resp *http.Response, err error) bool {
+	if err != nil {
+		var urlErr *url.Error
+		return errors.As(err, &urlErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.statuses[resp.StatusCode]
+}
+
+// doWithFailover sends a request for a relative path to each base URL in turn
+func (rg *RushGo) doWithFailover(method, path string, body []byte, opts *requestOptions) (*http.Response, error) {
+	candidates := rg.baseURLs.candidates(path)
+	if len(candidates) == 0 {
+		return rg.doWithReauth(method, path, body, opts)
+	}
+
+	var resp *http.Response
+	var err error
+	for i, full := range candidates {
+		resp, err = rg.doWithReauth(method, full, body, opts)
+		if !rg.baseURLs.shouldFailover(resp, err) {
+			if err == nil {
+				rg.baseURLs.mark(full, true)
+			}
+			return resp, err
+		}
+
+		rg.baseURLs.mark(full, false)
+		if i < len(candidates)-1 && resp != nil {
+			resp.Body.Close()
+		}
+	}
+	return resp, err
+}
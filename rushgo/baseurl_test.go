@@ -0,0 +1,110 @@
+package rushgo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBaseURLFailoverWithRetryOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) {
+		mu.Lock()
+		order = append(order, name)
+		mu.Unlock()
+	}
+
+	// a is down; b is overloaded once, which is retried but isn't a failover status
+	a := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record("a")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer a.Close()
+	var bHits int32
+	b := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		record("b")
+		if atomic.AddInt32(&bHits, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer b.Close()
+
+	rg := New(nil).
+		WithBaseURLs([]string{a.URL, b.URL}).
+		WithFailoverStatus(http.StatusServiceUnavailable).
+		WithRetry(2, time.Millisecond)
+
+	tests := []struct {
+		name string
+		want []string
+	}{
+		// The whole cycle runs per attempt; on the retry a is cooling down
+		// and goes behind b
+		{"first request", []string{"a", "b", "b"}},
+		// a is still cooling down for the next request
+		{"second request", []string{"b"}},
+	}
+	for _, tt := range tests {
+		mu.Lock()
+		order = nil
+		mu.Unlock()
+
+		resp, err := rg.Get("/ping")
+		if err != nil {
+			t.Fatalf("%s: Get: %v", tt.name, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("%s: status = %d, want 200", tt.name, resp.StatusCode)
+		}
+		mu.Lock()
+		got := order
+		mu.Unlock()
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: attempt order = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBaseURLRetryAfterAllFailed(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	var hits int32
+	server := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			if atomic.AddInt32(&hits, 1) <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+	}
+	a, b := server("a"), server("b")
+	defer a.Close()
+	defer b.Close()
+
+	rg := New(nil).
+		WithBaseURLs([]string{a.URL, b.URL}).
+		WithFailoverStatus(http.StatusServiceUnavailable).
+		WithRetry(1, time.Millisecond)
+
+	resp, err := rg.Get("/ping")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	// Both bases are cooling down, so the retry keeps the configured order
+	if want := []string{"a", "b", "a"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("attempt order = %v, want %v", order, want)
+	}
+}
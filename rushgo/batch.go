@@ -0,0 +1,171 @@
+package rushgo
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// GetManyOptions configures GetMany
+type GetManyOptions struct {
+	// Concurrency is the number of requests in flight at once, 10 if unset.
+	// In adaptive mode it is the starting and maximum concurrency.
+	Concurrency int
+
+	// Adaptive halves the concurrency when a request fails (error, timeout,
+	// 429 or 5xx) and grows it again by about one per round of successes,
+	// never going below MinConcurrency (1 if unset)
+	Adaptive       bool
+	MinConcurrency int
+}
+
+// GetManyResult is the outcome of fetching one URL. The body is read fully
+// and the response closed, so nothing has to be cleaned up.
+type GetManyResult struct {
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+	Err        error
+}
+
+// GetManySummary holds all results, in the same order as the URLs, and totals
+type GetManySummary struct {
+	Results   []GetManyResult
+	Succeeded int // requests that got a response, whatever its status
+	Failed    int
+
+	// EffectiveConcurrency is the concurrency in use when the batch finished,
+	// which in adaptive mode reflects how healthy the targets were
+	EffectiveConcurrency int
+}
+
+// GetMany fetches all URLs with GET, running up to opts.Concurrency requests at once
+func (rg *RushGo) GetMany(urls []string, opts GetManyOptions) *GetManySummary {
+	return rg.getMany(nil, urls, opts)
+}
+
+// getMany is GetMany bound to ctx, nil for none
+func (rg *RushGo) getMany(ctx context.Context, urls []string, opts GetManyOptions) *GetManySummary {
+	maxConcurrency := opts.Concurrency
+	if maxConcurrency <= 0 {
+		maxConcurrency = 10
+	}
+	minConcurrency := opts.MinConcurrency
+	if minConcurrency <= 0 || !opts.Adaptive {
+		minConcurrency = 1
+	}
+	if minConcurrency > maxConcurrency {
+		minConcurrency = maxConcurrency
+	}
+
+	limiter := &concurrencyLimiter{
+		limit:    float64(maxConcurrency),
+		min:      float64(minConcurrency),
+		max:      float64(maxConcurrency),
+		adaptive: opts.Adaptive,
+	}
+	limiter.cond = sync.NewCond(&limiter.mu)
+
+	summary := &GetManySummary{Results: make([]GetManyResult, len(urls))}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < maxConcurrency && i < len(urls); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				limiter.acquire()
+				result := rg.fetchForBatch(ctx, urls[index])
+				limiter.release(result.Err == nil && result.StatusCode != http.StatusTooManyRequests && result.StatusCode < 500)
+				summary.Results[index] = result
+			}
+		}()
+	}
+	for index := range urls {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, result := range summary.Results {
+		if result.Err != nil {
+			summary.Failed++
+		} else {
+			summary.Succeeded++
+		}
+	}
+	summary.EffectiveConcurrency = limiter.current()
+	return summary
+}
+
+func (rg *RushGo) fetchForBatch(ctx context.Context, url string) GetManyResult {
+	var result GetManyResult
+	rg.retryOnTruncation(ctx, func() error {
+		result = rg.fetchOnceForBatch(ctx, url)
+		return result.Err
+	})
+	return result
+}
+
+func (rg *RushGo) fetchOnceForBatch(ctx context.Context, url string) GetManyResult {
+	result := GetManyResult{URL: url}
+	resp, err := rg.do("GET", url, nil, &requestOptions{ctx: ctx})
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	defer resp.Body.Close()
+
+	result.StatusCode = resp.StatusCode
+	result.Header = resp.Header
+	result.Body, result.Err = io.ReadAll(resp.Body)
+	return result
+}
+
+// concurrencyLimiter lets at most limit callers through at once. In adaptive
+// mode limit follows AIMD: halved on failure, grown by 1/limit per success.
+type concurrencyLimiter struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	active   int
+	limit    float64
+	min, max float64
+	adaptive bool
+}
+
+func (l *concurrencyLimiter) acquire() {
+	l.mu.Lock()
+	for l.active >= int(l.limit) {
+		l.cond.Wait()
+	}
+	l.active++
+	l.mu.Unlock()
+}
+
+func (l *concurrencyLimiter) release(ok bool) {
+	l.mu.Lock()
+	l.active--
+	if l.adaptive {
+		if ok {
+			l.limit += 1 / l.limit
+			if l.limit > l.max {
+				l.limit = l.max
+			}
+		} else {
+			l.limit /= 2
+			if l.limit < l.min {
+				l.limit = l.min
+			}
+		}
+	}
+	l.mu.Unlock()
+	l.cond.Broadcast()
+}
+
+func (l *concurrencyLimiter) current() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
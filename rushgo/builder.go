@@ -0,0 +1,64 @@
+package rushgo
+
+import (
+	"net/http"
+)
+
+// RequestBuilder configures a single request on top of the client defaults.
+// Settings made on the builder never change the RushGo instance.
+type RequestBuilder struct {
+	rg     *RushGo
+	method string
+	url    string
+	body   []byte
+	opts   requestOptions
+}
+
+// NewRequest starts building a request with the given method and URL
+func (rg *RushGo) NewRequest(method, url string) *RequestBuilder {
+	return &RequestBuilder{
+		rg:     rg,
+		method: method,
+		url:    url,
+		opts:   requestOptions{headers: make(map[string]string)},
+	}
+}
+
+// Header sets a header for this request only, overriding any default
+func (b *RequestBuilder) Header(key, value string) *RequestBuilder {
+	b.opts.headers[key] = value
+	return b
+}
+
+// Body sets the request body
+func (b *RequestBuilder) Body(body []byte) *RequestBuilder {
+	b.body = body
+	return b
+}
+
+// Raw returns the response body exactly as the server sent it. Normally the
+// transport requests gzip and decompresses the body transparently, and gzip
+// or deflate bodies the transport leaves compressed are decoded as well; with
+// Raw the body stays compressed and the Content-Encoding header is left in
+// place.
+// An Accept-Encoding header set by the caller is sent unchanged.
+func (b *RequestBuilder) Raw() *RequestBuilder {
+	b.opts.raw = true
+	return b
+}
+
+// Tag labels the request for metrics, e.g. Tag("endpoint", "search") or
+// Tag("tenant", id). Tags are passed to the WithMetrics collector and can be
+// read back with Meta. They are never sent to the server.
+func (b *RequestBuilder) Tag(key, value string) *RequestBuilder {
+	if b.opts.tags == nil {
+		b.opts.tags = make(map[string]string)
+	}
+	b.opts.tags[key] = value
+	return b
+}
+
+// Do sends the request
+func (b *RequestBuilder) Do() (*http.Response, error) {
+	return b.rg.do(b.method, b.url, b.body, &b.opts)
+}
@@ -0,0 +1,192 @@
+package rushgo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// CassetteMode controls how a cassette set with WithCassette is used
+type CassetteMode int
+
+const (
+	// CassetteRecord sends every request and records the responses
+	CassetteRecord CassetteMode = iota
+	// CassetteReplay serves responses from the cassette only and fails
+	// requests that were never recorded
+	CassetteReplay
+	// CassetteAuto replays recorded responses and records the missing ones
+	CassetteAuto
+)
+
+// cassetteRequest and cassetteResponse are the JSON form of a recorded interaction
+type cassetteRequest struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   []byte `json:"body,omitempty"`
+}
+
+type cassetteResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body,omitempty"`
+}
+
+type cassetteInteraction struct {
+	Request  cassetteRequest  `json:"request"`
+	Response cassetteResponse `json:"response"`
+}
+
+type cassetteFile struct {
+	Interactions []cassetteInteraction `json:"interactions"`
+}
+
+// cassette records and replays interactions keyed by method, URL and body
+type cassette struct {
+	path string
+	mode CassetteMode
+
+	mu           sync.Mutex
+	interactions map[string]cassetteInteraction
+	order        []string // keys in recording order, so the file stays stable
+}
+
+// WithCassette records responses to the JSON file at path and/or replays them
+// from it, depending on mode, so tests can run without the network. Requests
+// are matched on method, URL and body, where the URL's scheme and host are
+// compared case-insensitively and a default port is ignored. In
+// CassetteReplay mode the file must exist.
+func (rg *RushGo) WithCassette(path string, mode CassetteMode) error {
+	c := &cassette{
+		path:         path,
+		mode:         mode,
+		interactions: make(map[string]cassetteInteraction),
+	}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		var file cassetteFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("failed to parse cassette %s: %w", path, err)
+		}
+		for _, interaction := range file.Interactions {
+			c.add(interaction)
+		}
+	case errors.Is(err, os.ErrNotExist) && mode != CassetteReplay:
+		// A new cassette is written on the first recording
+	default:
+		return fmt.Errorf("failed to open cassette %s: %w", path, err)
+	}
+
+	rg.cassette = c
+	return nil
+}
+
+func cassetteKey(method, url string, body []byte) string {
+	sum := sha256.Sum256(body)
+	return method + " " + normalizeURLKey(url) + " " + hex.EncodeToString(sum[:])
+}
+
+func (c *cassette) add(interaction cassetteInteraction) {
+	key := cassetteKey(interaction.Request.Method, interaction.Request.URL, interaction.Request.Body)
+	if _, exists := c.interactions[key]; !exists {
+		c.order = append(c.order, key)
+	}
+	c.interactions[key] = interaction
+}
+
+// roundTrip replays or records req, calling send for requests that go to the network
+func (c *cassette) roundTrip(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	key := cassetteKey(req.Method, req.URL.String(), body)
+
+	if c.mode != CassetteRecord {
+		c.mu.Lock()
+		interaction, ok := c.interactions[key]
+		c.mu.Unlock()
+		if ok {
+			if meta := metaFromContext(req.Context()); meta != nil {
+				meta.Cached = true
+			}
+			return interaction.Response.toHTTP(req), nil
+		}
+		if c.mode == CassetteReplay {
+			return nil, fmt.Errorf("no recorded response in cassette %s for %s %s", c.path, req.Method, req.URL)
+		}
+	}
+
+	resp, err := send(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := cassetteInteraction{
+		Request:  cassetteRequest{Method: req.Method, URL: req.URL.String(), Body: body},
+		Response: cassetteResponse{StatusCode: resp.StatusCode, Header: resp.Header, Body: respBody},
+	}
+	if err := c.record(interaction); err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+// record adds the interaction and rewrites the cassette file
+func (c *cassette) record(interaction cassetteInteraction) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.add(interaction)
+	file := cassetteFile{Interactions: make([]cassetteInteraction, 0, len(c.order))}
+	for _, key := range c.order {
+		file.Interactions = append(file.Interactions, c.interactions[key])
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette %s: %w", c.path, err)
+	}
+	return nil
+}
+
+// toHTTP builds a fresh *http.Response from a recorded response
+func (r cassetteResponse) toHTTP(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        strconv.Itoa(r.StatusCode) + " " + http.StatusText(r.StatusCode),
+		StatusCode:    r.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        r.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       req,
+	}
+}
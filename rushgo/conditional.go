@@ -0,0 +1,40 @@
+package rushgo
+
+import (
+	"errors"
+	"net/http"
+)
+
+// PutIfMatch makes a PUT request that only succeeds if the resource still has
+// the given ETag, for optimistic concurrency control. If the resource changed
+// the server answers 412, which is returned as a *StatusError; check for it
+// with IsPreconditionFailed.
+func (rg *RushGo) PutIfMatch(url string, body []byte, etag string) (*http.Response, error) {
+	return rg.putConditional(url, body, "If-Match", etag)
+}
+
+// PutIfNoneMatch makes a PUT request that only succeeds if the resource does
+// not match etag. Pass "*" to create the resource only if it does not exist
+// yet. A 412 response is returned as a *StatusError, like PutIfMatch.
+func (rg *RushGo) PutIfNoneMatch(url string, body []byte, etag string) (*http.Response, error) {
+	return rg.putConditional(url, body, "If-None-Match", etag)
+}
+
+func (rg *RushGo) putConditional(url string, body []byte, header, etag string) (*http.Response, error) {
+	resp, err := rg.do("PUT", url, body, &requestOptions{headers: map[string]string{header: etag}})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusPreconditionFailed {
+		defer resp.Body.Close()
+		return nil, newStatusError(resp)
+	}
+	return resp, nil
+}
+
+// IsPreconditionFailed reports whether err is a 412 Precondition Failed
+// response, as returned by PutIfMatch and PutIfNoneMatch
+func IsPreconditionFailed(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusPreconditionFailed
+}
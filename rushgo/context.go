@@ -0,0 +1,141 @@
+package rushgo
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// GetCtx makes a GET request bound to ctx. Canceling ctx, or its deadline
+// passing, aborts the request, any retry wait and the body read with
+// context.Canceled or context.DeadlineExceeded.
+func (rg *RushGo) GetCtx(ctx context.Context, url string) (*http.Response, error) {
+	return rg.do("GET", url, nil, &requestOptions{ctx: ctx})
+}
+
+// PostCtx makes a POST request bound to ctx, like GetCtx
+func (rg *RushGo) PostCtx(ctx context.Context, url string, body []byte) (*http.Response, error) {
+	return rg.do("POST", url, body, &requestOptions{ctx: ctx})
+}
+
+// PutCtx makes a PUT request bound to ctx, like GetCtx
+func (rg *RushGo) PutCtx(ctx context.Context, url string, body []byte) (*http.Response, error) {
+	return rg.do("PUT", url, body, &requestOptions{ctx: ctx})
+}
+
+// PatchCtx makes a PATCH request bound to ctx, like GetCtx
+func (rg *RushGo) PatchCtx(ctx context.Context, url string, body []byte) (*http.Response, error) {
+	return rg.do("PATCH", url, body, &requestOptions{ctx: ctx})
+}
+
+// DeleteCtx makes a DELETE request bound to ctx, like GetCtx
+func (rg *RushGo) DeleteCtx(ctx context.Context, url string) (*http.Response, error) {
+	return rg.do("DELETE", url, nil, &requestOptions{ctx: ctx})
+}
+
+// HeadCtx makes a HEAD request bound to ctx, like GetCtx
+func (rg *RushGo) HeadCtx(ctx context.Context, url string) (*http.Response, error) {
+	return rg.do("HEAD", url, nil, &requestOptions{ctx: ctx})
+}
+
+// OptionsCtx makes an OPTIONS request bound to ctx, like GetCtx
+func (rg *RushGo) OptionsCtx(ctx context.Context, url string) (*http.Response, error) {
+	return rg.do("OPTIONS", url, nil, &requestOptions{ctx: ctx})
+}
+
+// GetJSONCtx is GetJSON bound to ctx, like GetCtx
+func (rg *RushGo) GetJSONCtx(ctx context.Context, url string, v interface{}) error {
+	return rg.getJSON(ctx, url, v)
+}
+
+// PostJSONCtx is PostJSON bound to ctx, like GetCtx
+func (rg *RushGo) PostJSONCtx(ctx context.Context, url string, v interface{}) (*http.Response, error) {
+	return rg.sendJSON(ctx, "POST", url, v)
+}
+
+// PutJSONCtx is PutJSON bound to ctx, like GetCtx
+func (rg *RushGo) PutJSONCtx(ctx context.Context, url string, v interface{}) (*http.Response, error) {
+	return rg.sendJSON(ctx, "PUT", url, v)
+}
+
+// PatchJSONCtx is PatchJSON bound to ctx, like GetCtx
+func (rg *RushGo) PatchJSONCtx(ctx context.Context, url string, v interface{}) (*http.Response, error) {
+	return rg.sendJSON(ctx, "PATCH", url, v)
+}
+
+// GetJSONArrayCtx is GetJSONArray bound to ctx. Canceling ctx stops the
+// decode between elements, or mid-element, with ctx's error.
+func GetJSONArrayCtx[T any](ctx context.Context, rg *RushGo, url string, onItem func(T) error) error {
+	return getJSONArray(ctx, rg, url, onItem)
+}
+
+// GetLinesCtx is GetLines bound to ctx. Canceling ctx while the body is
+// streaming stops it with ctx's error.
+func (rg *RushGo) GetLinesCtx(ctx context.Context, url string, onLine func(string) error) error {
+	return rg.getLines(ctx, url, onLine)
+}
+
+// GetChunkedCtx is GetChunked bound to ctx, like GetLinesCtx. Resumes after a
+// truncated body are bound to ctx too.
+func (rg *RushGo) GetChunkedCtx(ctx context.Context, url string, onChunk func([]byte) error) error {
+	return rg.getChunked(ctx, url, onChunk)
+}
+
+// GetManyCtx is GetMany bound to ctx. Canceling ctx fails the requests still
+// queued or in flight with ctx's error; the summary is still returned.
+func (rg *RushGo) GetManyCtx(ctx context.Context, urls []string, opts GetManyOptions) *GetManySummary {
+	return rg.getMany(ctx, urls, opts)
+}
+
+// WebSocketConnectCtx is WebSocketConnect with the handshake bound to ctx.
+// ctx only covers dialing; the returned connection outlives it.
+func (rg *RushGo) WebSocketConnectCtx(ctx context.Context, urlStr string) (*websocket.Conn, *http.Response, error) {
+	return rg.webSocketConnect(ctx, urlStr, WebSocketOptions{})
+}
+
+// WebSocketConnectWithOptionsCtx is WebSocketConnectWithOptions with the
+// handshake bound to ctx, like WebSocketConnectCtx
+func (rg *RushGo) WebSocketConnectWithOptionsCtx(ctx context.Context, urlStr string, opts WebSocketOptions) (*websocket.Conn, *http.Response, error) {
+	return rg.webSocketConnect(ctx, urlStr, opts)
+}
+
+// Context binds the request to ctx, like GetCtx. It is also the way to bind
+// per-call headers to a context, in place of ctx variants of the
+// *WithHeaders methods:
+//
+//	rg.NewRequest("GET", url).Header("X-Trace", id).Context(ctx).Do()
+func (b *RequestBuilder) Context(ctx context.Context) *RequestBuilder {
+	b.opts.ctx = ctx
+	return b
+}
+
+// mergeContext returns a context that is canceled when either ctx or root is.
+// The returned cancel must be called to release it.
+func mergeContext(ctx, root context.Context) (context.Context, context.CancelFunc) {
+	merged, cancel := context.WithCancel(ctx)
+	go func() {
+		select {
+		case <-root.Done():
+			cancel()
+		case <-merged.Done():
+		}
+	}()
+	return merged, cancel
+}
+
+// sleepContext waits for d, returning early with the error if ctx ends first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
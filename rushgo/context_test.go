@@ -0,0 +1,102 @@
+package rushgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// stallingServer sends the start of a body, then holds the response open
+// until the client goes away
+func stallingServer(t *testing.T, contentType, start string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Write([]byte(start))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+}
+
+func TestCtxVariantsCancelMidBody(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		start       string
+		read        func(ctx context.Context, rg *RushGo, url string, started func()) error
+	}{
+		{"GetLinesCtx", "text/plain", "first\n", func(ctx context.Context, rg *RushGo, url string, started func()) error {
+			return rg.GetLinesCtx(ctx, url, func(string) error { started(); return nil })
+		}},
+		{"GetChunkedCtx", "application/octet-stream", "first", func(ctx context.Context, rg *RushGo, url string, started func()) error {
+			return rg.WithChunkSize(1).GetChunkedCtx(ctx, url, func([]byte) error { started(); return nil })
+		}},
+		{"GetJSONArrayCtx", "application/json", "[1,", func(ctx context.Context, rg *RushGo, url string, started func()) error {
+			return GetJSONArrayCtx(ctx, rg, url, func(int) error { started(); return nil })
+		}},
+		{"GetJSONCtx", "application/json", `{"a":`, func(ctx context.Context, rg *RushGo, url string, started func()) error {
+			// GetJSON has no callback, so treat the headers as the start
+			started()
+			var v map[string]int
+			return rg.GetJSONCtx(ctx, url, &v)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := stallingServer(t, tt.contentType, tt.start)
+			defer srv.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			started := make(chan struct{})
+			var once bool
+			done := make(chan error, 1)
+			go func() {
+				done <- tt.read(ctx, New(nil), srv.URL, func() {
+					if !once {
+						once = true
+						close(started)
+					}
+				})
+			}()
+
+			select {
+			case <-started:
+			case err := <-done:
+				t.Fatalf("returned before the body started: %v", err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("body never started")
+			}
+			cancel()
+
+			select {
+			case err := <-done:
+				if !errors.Is(err, context.Canceled) {
+					t.Fatalf("err = %v, want context.Canceled", err)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("cancel did not stop the body read")
+			}
+		})
+	}
+}
+
+func TestGetManyCtxCanceled(t *testing.T) {
+	srv := stallingServer(t, "text/plain", "")
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	summary := New(nil).GetManyCtx(ctx, []string{srv.URL, srv.URL}, GetManyOptions{Concurrency: 1})
+	if summary.Failed != 2 {
+		t.Fatalf("Failed = %d, want 2", summary.Failed)
+	}
+	for _, result := range summary.Results {
+		if !errors.Is(result.Err, context.DeadlineExceeded) {
+			t.Fatalf("err = %v, want context.DeadlineExceeded", result.Err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package rushgo
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// decoders open a decompressing reader for each supported Content-Encoding.
+// Brotli (br) would need a third-party decoder, so br bodies are passed
+// through as sent and br is never offered: the browser presets leave it out
+// and Replay drops it from recorded Accept-Encoding headers.
+var decoders = map[string]func(io.Reader) (io.Reader, error){
+	"gzip":   func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+	"x-gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+	"deflate": func(r io.Reader) (io.Reader, error) {
+		// deflate is meant to be zlib-wrapped, but some servers send a raw
+		// deflate stream, so check for the zlib header first
+		br := bufio.NewReader(r)
+		header, err := br.Peek(2)
+		switch {
+		case len(header) == 0 && err == io.EOF:
+			return br, nil
+		case len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0:
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	},
+}
+
+// WithoutDecompression returns every response body exactly as the server sent
+// it, like RequestBuilder.Raw does for a single request
+func (rg *RushGo) WithoutDecompression() *RushGo {
+	rg.rawResponses = true
+	return rg
+}
+
+// supportedAcceptEncoding removes the codings decoders can't undo, such as br
+// and zstd, from an Accept-Encoding value, keeping the order and any q
+// values. It returns "" if nothing supported is left.
+func supportedAcceptEncoding(value string) string {
+	var kept []string
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		coding, _, _ := strings.Cut(item, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if _, ok := decoders[coding]; ok || coding == "identity" || coding == "*" {
+			kept = append(kept, item)
+		}
+	}
+	return strings.Join(kept, ", ")
+}
+
+// decompressBody decodes a compressed response the transport left alone. The
+// transport only handles gzip, and only when it added Accept-Encoding itself,
+// so a caller who sets Accept-Encoding by hand (directly or through a browser
+// preset), or a server that compresses unasked, would otherwise hand back
+// compressed bytes. gzip and deflate are supported, also stacked as in
+// "deflate, gzip"; any other coding leaves the response untouched. The
+// response is rewritten the way the transport does it: Content-Encoding and
+// Content-Length are dropped and Uncompressed is set. Partial content is left
+// alone since a byte range of a compressed stream can't be decoded on its own,
+// and so are HEAD requests and bodyless statuses, whose Content-Length
+// describes the resource rather than a body here.
+func decompressBody(resp *http.Response) {
+	encoding := resp.Header.Get("Content-Encoding")
+	if resp.Uncompressed || resp.StatusCode == http.StatusPartialContent || encoding == "" || !hasResponseBody(resp) {
+		return
+	}
+
+	// Codings are listed in the order they were applied, so undo them from
+	// the last one
+	codings := strings.Split(encoding, ",")
+	opens := make([]func(io.Reader) (io.Reader, error), 0, len(codings))
+	for i := len(codings) - 1; i >= 0; i-- {
+		coding := strings.ToLower(strings.TrimSpace(codings[i]))
+		if coding == "identity" {
+			continue
+		}
+		open, ok := decoders[coding]
+		if !ok {
+			return
+		}
+		opens = append(opens, open)
+	}
+	if len(opens) == 0 {
+		return
+	}
+
+	resp.Body = &decodedBody{body: resp.Body, opens: opens}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+}
+
+// decodedBody opens the decoders on first read, so empty bodies such as HEAD
+// and 304 responses just read as EOF
+type decodedBody struct {
+	body  io.ReadCloser
+	opens []func(io.Reader) (io.Reader, error)
+	r     io.Reader
+	err   error
+}
+
+func (d *decodedBody) Read(p []byte) (int, error) {
+	if d.r == nil && d.err == nil {
+		d.r = d.body
+		for _, open := range d.opens {
+			if d.r, d.err = open(d.r); d.err != nil {
+				break
+			}
+		}
+	}
+	if d.err != nil {
+		return 0, d.err
+	}
+	return d.r.Read(p)
+}
+
+func (d *decodedBody) Close() error {
+	return d.body.Close()
+}
+
+// hasResponseBody reports whether resp can carry a body: not the answer to a
+// HEAD request, nor 1xx, 204 or 304
+func hasResponseBody(resp *http.Response) bool {
+	if resp.Request != nil && resp.Request.Method == http.MethodHead {
+		return false
+	}
+	code := resp.StatusCode
+	return code >= 200 && code != http.StatusNoContent && code != http.StatusNotModified
+}
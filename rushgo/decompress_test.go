@@ -0,0 +1,192 @@
+package rushgo
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const plaintext = "hello, decompressed world"
+
+// compress encodes plaintext with the named coding
+func compress(t *testing.T, coding string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch coding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "flate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	}
+	w.Write([]byte(plaintext))
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestDecompressBody(t *testing.T) {
+	bodies := map[string][]byte{
+		"/gzip":         compress(t, "gzip"),
+		"/deflate-zlib": compress(t, "zlib"),
+		"/deflate-raw":  compress(t, "flate"),
+	}
+	encodings := map[string]string{
+		"/gzip":         "gzip",
+		"/deflate-zlib": "deflate",
+		"/deflate-raw":  "deflate",
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", encodings[r.URL.Path])
+		w.Write(bodies[r.URL.Path])
+	}))
+	defer srv.Close()
+
+	// Setting Accept-Encoding by hand stops the transport from decoding gzip
+	rg := New(nil).WithHeaders(map[string]string{"Accept-Encoding": "gzip, deflate"})
+	for path := range bodies {
+		resp, err := rg.Get(srv.URL + path)
+		if err != nil {
+			t.Fatalf("%s: Get: %v", path, err)
+		}
+		body, err := BodyString(resp)
+		if err != nil {
+			t.Fatalf("%s: read body: %v", path, err)
+		}
+		if body != plaintext {
+			t.Errorf("%s: body = %q, want %q", path, body, plaintext)
+		}
+		if got := resp.Header.Get("Content-Encoding"); got != "" {
+			t.Errorf("%s: Content-Encoding = %q, want it removed", path, got)
+		}
+	}
+
+	resp, err := rg.NewRequest("GET", srv.URL+"/gzip").Raw().Do()
+	if err != nil {
+		t.Fatalf("Raw: %v", err)
+	}
+	body, err := BodyBytes(resp)
+	if err != nil {
+		t.Fatalf("Raw: read body: %v", err)
+	}
+	if !bytes.Equal(body, bodies["/gzip"]) {
+		t.Errorf("Raw body = %q, want the gzip bytes as sent", body)
+	}
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("Raw Content-Encoding = %q, want gzip", got)
+	}
+}
+
+func TestDecompressKeepsBodylessHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Set("Content-Length", "1234")
+		if r.URL.Path == "/not-modified" {
+			w.WriteHeader(http.StatusNotModified)
+		}
+	}))
+	defer srv.Close()
+
+	rg := New(nil).WithHeaders(map[string]string{"Accept-Encoding": "gzip, deflate"})
+
+	resp, err := rg.Head(srv.URL + "/file")
+	if err != nil {
+		t.Fatalf("Head: %v", err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("HEAD Content-Encoding = %q, want gzip", got)
+	}
+	if got := resp.Header.Get("Content-Length"); got != "1234" {
+		t.Errorf("HEAD Content-Length = %q, want 1234", got)
+	}
+
+	resp, err = rg.Get(srv.URL + "/not-modified")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Errorf("304 Content-Encoding = %q, want gzip", got)
+	}
+}
+
+// brotliPreferringServer answers in br whenever the client offers it, like
+// many CDNs, and in gzip otherwise
+func brotliPreferringServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	gzipped := compress(t, "gzip")
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, coding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+			if strings.TrimSpace(coding) == "br" {
+				w.Header().Set("Content-Encoding", "br")
+				w.Write([]byte("\x0b\x02\x80not really brotli"))
+				return
+			}
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(gzipped)
+	}))
+}
+
+func TestBrowserPresetBodiesAreDecoded(t *testing.T) {
+	srv := brotliPreferringServer(t)
+	defer srv.Close()
+
+	for _, browser := range []string{"chrome", "firefox", "safari"} {
+		resp, err := New(nil).WithBrowserPreset(browser).Get(srv.URL)
+		if err != nil {
+			t.Fatalf("%s: Get: %v", browser, err)
+		}
+		body, err := BodyString(resp)
+		if err != nil {
+			t.Fatalf("%s: read body: %v", browser, err)
+		}
+		if body != plaintext {
+			t.Errorf("%s: body = %q, want %q", browser, body, plaintext)
+		}
+	}
+}
+
+func TestReplayDropsUnsupportedEncodings(t *testing.T) {
+	srv := brotliPreferringServer(t)
+	defer srv.Close()
+
+	entry := HAREntry{Request: HARRequest{
+		Method:  "GET",
+		URL:     srv.URL,
+		Headers: []HARNameValue{{Name: "accept-encoding", Value: "gzip, deflate, br, zstd"}},
+	}}
+	resp, err := New(nil).Replay(entry)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	body, err := BodyString(resp)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	if body != plaintext {
+		t.Errorf("body = %q, want %q", body, plaintext)
+	}
+}
+
+func TestSupportedAcceptEncoding(t *testing.T) {
+	tests := map[string]string{
+		"gzip, deflate, br":           "gzip, deflate",
+		"br;q=1.0, gzip;q=0.8, *;q=0": "gzip;q=0.8, *;q=0",
+		"br, zstd":                    "",
+		"identity":                    "identity",
+	}
+	for in, want := range tests {
+		if got := supportedAcceptEncoding(in); got != want {
+			t.Errorf("supportedAcceptEncoding(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package rushgo
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+)
+
+// WithTCPKeepAlive sets how often the OS sends TCP keep-alive probes on idle
+// connections, which detects dead peers (e.g. behind firewalls that silently
+// drop idle connections) sooner. This is separate from HTTP keep-alive, which
+// is about reusing connections between requests. Zero disables the probes.
+// It only applies to the HTTP/1.1 and HTTP/2 transport.
+func (rg *RushGo) WithTCPKeepAlive(interval time.Duration) *RushGo {
+	if interval == 0 {
+		// net.Dialer treats zero as "use the default" and a negative value as off
+		interval = -1
+	}
+	rg.dialer.KeepAlive = interval
+	rg.applyDialer()
+	return rg
+}
+
+// applyDialer installs the client's dialer, and the DNS cache and connection
+// deadlines if enabled, on the transport
+func (rg *RushGo) applyDialer() {
+	transport, ok := rg.client.Transport.(*http.Transport)
+	if !ok {
+		return
+	}
+	dial := rg.dialer.DialContext
+	if rg.dnsCache != nil {
+		dial = rg.dnsCache.dialContext(rg.dialer)
+	}
+	if rg.readDeadline > 0 || rg.writeDeadline > 0 {
+		read, write, next := rg.readDeadline, rg.writeDeadline, dial
+		dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := next(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return &deadlineConn{Conn: conn, read: read, write: write}, nil
+		}
+	}
+	transport.DialContext = dial
+}
+
+// WithConnDeadlines fails a request when a single read from or write to its
+// connection makes no progress for the given time. The deadline restarts with
+// every read or write, so unlike WithTimeout, which caps the whole request,
+// it lets a large download run as long as it needs while still catching a
+// server that stops sending, or trickles bytes just fast enough to hold the
+// connection open. The read deadline also covers the wait for the response
+// to start, and idle keep-alive connections are closed once it passes. Zero
+// turns a deadline off. It only applies to the HTTP/1.1 and HTTP/2 transport.
+func (rg *RushGo) WithConnDeadlines(read, write time.Duration) *RushGo {
+	rg.readDeadline = read
+	rg.writeDeadline = write
+	rg.applyDialer()
+	return rg
+}
+
+// deadlineConn pushes the read or write deadline forward before every read or
+// write
+type deadlineConn struct {
+	net.Conn
+	read, write time.Duration
+}
+
+func (c *deadlineConn) Read(b []byte) (int, error) {
+	if c.read > 0 {
+		if err := c.Conn.SetReadDeadline(time.Now().Add(c.read)); err != nil {
+			return 0, err
+		}
+	}
+	return c.Conn.Read(b)
+}
+
+func (c *deadlineConn) Write(b []byte) (int, error) {
+	if c.write > 0 {
+		if err := c.Conn.SetWriteDeadline(time.Now().Add(c.write)); err != nil {
+			return 0, err
+		}
+	}
+	return c.Conn.Write(b)
+}
@@ -0,0 +1,83 @@
+package rushgo
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// digestAlgorithms maps RFC 3230 / RFC 5843 digest names to hash constructors
+var digestAlgorithms = map[string]func() hash.Hash{
+	"sha-512": sha512.New,
+	"sha-256": sha256.New,
+	"sha":     sha1.New,
+	"md5":     md5.New,
+}
+
+// WithDigestVerification checks response bodies against the server's Digest
+// header (RFC 3230, e.g. "sha-256=<base64>"). Once the body is read to the end
+// a mismatch makes the final Read fail with an error wrapping
+// ErrChecksumMismatch. sha-512, sha-256, sha and md5 are supported; responses
+// without a usable Digest, partial responses and bodies the transport
+// decompressed are passed through unchecked.
+func (rg *RushGo) WithDigestVerification() *RushGo {
+	rg.verifyDigest = true
+	return rg
+}
+
+// wrapDigest returns resp's body wrapped to verify the Digest header, or the
+// body unchanged when there is nothing to verify
+func wrapDigest(resp *http.Response) io.ReadCloser {
+	if resp.Uncompressed || resp.StatusCode == http.StatusPartialContent {
+		return resp.Body
+	}
+
+	// Use the strongest algorithm the server offers
+	offered := map[string]string{}
+	for _, entry := range strings.Split(resp.Header.Get("Digest"), ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(entry), "=")
+		if found {
+			offered[strings.ToLower(name)] = strings.TrimSpace(value)
+		}
+	}
+	for _, name := range []string{"sha-512", "sha-256", "sha", "md5"} {
+		value, ok := offered[name]
+		if !ok {
+			continue
+		}
+		want, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		return &digestReader{ReadCloser: resp.Body, name: name, hash: digestAlgorithms[name](), want: want}
+	}
+	return resp.Body
+}
+
+// digestReader hashes the body as it is read and checks it at EOF
+type digestReader struct {
+	io.ReadCloser
+	name string
+	hash hash.Hash
+	want []byte
+}
+
+func (d *digestReader) Read(p []byte) (int, error) {
+	n, err := d.ReadCloser.Read(p)
+	d.hash.Write(p[:n])
+	if err == io.EOF {
+		if got := d.hash.Sum(nil); !bytes.Equal(got, d.want) {
+			return n, fmt.Errorf("%w: Digest %s=%s, body hashes to %s", ErrChecksumMismatch,
+				d.name, base64.StdEncoding.EncodeToString(d.want), base64.StdEncoding.EncodeToString(got))
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,108 @@
+package rushgo
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+// dnsCache caches resolved addresses per host for a fixed TTL
+type dnsCache struct {
+	ttl      time.Duration
+	resolver *net.Resolver
+
+	mu      sync.Mutex
+	entries map[string]*dnsEntry
+}
+
+type dnsEntry struct {
+	addrs   []string
+	expires time.Time
+	next    int // index of the address to try first, for round-robin
+}
+
+func newDNSCache(ttl time.Duration) *dnsCache {
+	return &dnsCache{
+		ttl:      ttl,
+		resolver: net.DefaultResolver,
+		entries:  make(map[string]*dnsEntry),
+	}
+}
+
+// lookup returns the addresses for host, rotated so consecutive calls start
+// with a different address
+func (c *dnsCache) lookup(ctx context.Context, host string) ([]string, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[host]
+	if !ok || time.Now().After(entry.expires) {
+		c.mu.Unlock()
+		addrs, err := c.resolver.LookupHost(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		entry = &dnsEntry{addrs: addrs, expires: time.Now().Add(c.ttl)}
+		c.entries[host] = entry
+	}
+
+	rotated := make([]string, 0, len(entry.addrs))
+	rotated = append(rotated, entry.addrs[entry.next:]...)
+	rotated = append(rotated, entry.addrs[:entry.next]...)
+	entry.next = (entry.next + 1) % len(entry.addrs)
+	c.mu.Unlock()
+
+	return rotated, nil
+}
+
+func (c *dnsCache) flush() {
+	c.mu.Lock()
+	c.entries = make(map[string]*dnsEntry)
+	c.mu.Unlock()
+}
+
+// dialContext resolves the host through the cache and dials its addresses in
+// turn until one connects
+func (c *dnsCache) dialContext(dialer *net.Dialer) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if net.ParseIP(host) != nil {
+			return dialer.DialContext(ctx, network, addr)
+		}
+
+		addrs, err := c.lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		var lastErr error
+		for _, ip := range addrs {
+			conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip, port))
+			if err == nil {
+				return conn, nil
+			}
+			lastErr = err
+		}
+		return nil, lastErr
+	}
+}
+
+// WithDNSCache caches resolved host addresses for ttl so repeated requests to
+// the same hosts skip the DNS lookup. When a host has several A/AAAA records
+// new connections rotate through them. It only applies to the HTTP/1.1 and
+// HTTP/2 transport.
+func (rg *RushGo) WithDNSCache(ttl time.Duration) *RushGo {
+	rg.dnsCache = newDNSCache(ttl)
+	rg.applyDialer()
+	return rg
+}
+
+// FlushDNSCache drops every cached DNS entry
+func (rg *RushGo) FlushDNSCache() {
+	if rg.dnsCache != nil {
+		rg.dnsCache.flush()
+	}
+}
@@ -0,0 +1,532 @@
+package rushgo
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrChecksumMismatch is returned when a downloaded body does not match the
+// checksum the server sent for it
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// ErrDownloadGap is returned when the byte ranges written to a file don't
+// cover it exactly: a range is missing, written twice or runs past its end
+var ErrDownloadGap = errors.New("downloaded ranges don't cover the file")
+
+// ErrDownloadCanceled matches a *DownloadCanceledError with errors.Is
+var ErrDownloadCanceled = errors.New("download canceled")
+
+// DownloadCanceledError is returned when a download's context is canceled or
+// its deadline passes while the body is being written. It unwraps to the
+// cause, so errors.Is(err, context.Canceled) works too.
+type DownloadCanceledError struct {
+	Written int64 // bytes written to the file before the download stopped
+	Err     error
+}
+
+func (e *DownloadCanceledError) Error() string {
+	return fmt.Sprintf("download canceled after %d bytes: %v", e.Written, e.Err)
+}
+
+func (e *DownloadCanceledError) Unwrap() error { return e.Err }
+
+func (e *DownloadCanceledError) Is(target error) bool { return target == ErrDownloadCanceled }
+
+// isCanceled reports whether err comes from a canceled or expired context
+func isCanceled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// checksumTrailer is the trailer VerifyTrailerChecksum checks the body against
+const checksumTrailer = "X-Checksum-Sha256"
+
+// DownloadOption configures a single download
+type DownloadOption func(*downloadConfig)
+
+type downloadConfig struct {
+	accept        string // Accept header sent with the download request
+	verifyTrailer bool
+	image         bool // DownloadImage rather than DownloadFile
+	onProgress    func(written, total int64)
+	stats         *DownloadStats // filled in by a successful download
+}
+
+func newDownloadConfig(opts []DownloadOption) *downloadConfig {
+	cfg := &downloadConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// imageDownload marks a download made through DownloadImage
+func imageDownload(cfg *downloadConfig) {
+	cfg.image = true
+}
+
+// acceptHeader returns the Accept header to send, if any
+func (cfg *downloadConfig) acceptHeader() string {
+	if cfg.accept == "" && cfg.image {
+		return "image/*"
+	}
+	return cfg.accept
+}
+
+// kind names what is being downloaded, for error messages
+func (cfg *downloadConfig) kind() string {
+	if cfg.image {
+		return "image"
+	}
+	return "file"
+}
+
+// fallbackExt is the extension for a saved file whose type has no known one
+func (cfg *downloadConfig) fallbackExt() string {
+	if cfg.image {
+		return ".jpg"
+	}
+	return ""
+}
+
+// AcceptFormat asks content-negotiating servers for a specific format, e.g.
+// "image/webp", by sending it as the Accept header
+func AcceptFormat(mimeType string) DownloadOption {
+	return func(cfg *downloadConfig) {
+		cfg.accept = mimeType
+	}
+}
+
+// VerifyTrailerChecksum checks the downloaded body against the SHA-256 the
+// server sends in the X-Checksum-Sha256 trailer (hex or base64) once the body
+// is fully read. If the trailer is missing or does not match, the saved file
+// is deleted and an error is returned.
+func VerifyTrailerChecksum() DownloadOption {
+	return func(cfg *downloadConfig) {
+		cfg.verifyTrailer = true
+	}
+}
+
+// progressInterval is the shortest time between two progress callbacks
+const progressInterval = 100 * time.Millisecond
+
+// OnProgress calls onProgress with the bytes written so far and the total
+// size from Content-Length, or -1 if the server didn't send one, while the
+// body is saved. It is called at most every 100ms, plus once when the body is
+// complete.
+func OnProgress(onProgress func(written, total int64)) DownloadOption {
+	return func(cfg *downloadConfig) {
+		cfg.onProgress = onProgress
+	}
+}
+
+// DownloadFileWithProgress is DownloadFile saving to savePath with an
+// OnProgress callback
+func (rg *RushGo) DownloadFileWithProgress(url, savePath string, onProgress func(written, total int64)) (*http.Response, error) {
+	return rg.DownloadFile(url, &savePath, OnProgress(onProgress))
+}
+
+// DownloadStats summarizes a finished download
+type DownloadStats struct {
+	Bytes      int64         // body bytes written to the file
+	Duration   time.Duration // from sending the request to the last byte
+	Throughput float64       // bytes per second while the body was streamed
+}
+
+// DownloadWithStats is DownloadFile saving to destPath that reports how much
+// was transferred and how fast. Throughput leaves out the time to the first
+// response byte, so it measures the transfer rate of the connection.
+func (rg *RushGo) DownloadWithStats(url, destPath string) (DownloadStats, error) {
+	var stats DownloadStats
+	_, err := rg.DownloadFile(url, &destPath, func(cfg *downloadConfig) {
+		cfg.stats = &stats
+	})
+	return stats, err
+}
+
+// record fills in the stats of a download that has just completed
+func (cfg *downloadConfig) record(written int64, start, copyStart time.Time) {
+	if cfg.stats == nil {
+		return
+	}
+	now := time.Now()
+	*cfg.stats = DownloadStats{Bytes: written, Duration: now.Sub(start)}
+	if elapsed := now.Sub(copyStart).Seconds(); elapsed > 0 {
+		cfg.stats.Throughput = float64(written) / elapsed
+	}
+}
+
+// bodyReader returns the response body, counted for the progress callback
+// if one is set
+func (cfg *downloadConfig) bodyReader(resp *http.Response) io.Reader {
+	if cfg.onProgress == nil {
+		return resp.Body
+	}
+	return &downloadProgress{r: resp.Body, total: resp.ContentLength, onProgress: cfg.onProgress}
+}
+
+// downloadProgress counts the bytes read and reports them every
+// progressInterval and at EOF
+type downloadProgress struct {
+	r          io.Reader
+	written    int64
+	total      int64
+	last       time.Time
+	onProgress func(written, total int64)
+}
+
+func (p *downloadProgress) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.written += int64(n)
+	if err == io.EOF || (n > 0 && time.Since(p.last) >= progressInterval) {
+		p.last = time.Now()
+		p.onProgress(p.written, p.total)
+	}
+	return n, err
+}
+
+// bodyWriter returns where the body should be copied to and a hash that is fed
+// alongside the destination when the trailer checksum has to be verified
+func (cfg *downloadConfig) bodyWriter(dst io.Writer) (io.Writer, hash.Hash) {
+	if !cfg.verifyTrailer {
+		return dst, nil
+	}
+	sum := sha256.New()
+	return io.MultiWriter(dst, sum), sum
+}
+
+// verify compares the trailer checksum with the hash of the body. Trailers are
+// only available once the body has been read to the end.
+func (cfg *downloadConfig) verify(resp *http.Response, sum hash.Hash) error {
+	if !cfg.verifyTrailer {
+		return nil
+	}
+
+	declared := strings.TrimSpace(resp.Trailer.Get(checksumTrailer))
+	if declared == "" {
+		return fmt.Errorf("server sent no %s trailer to verify the download against", checksumTrailer)
+	}
+
+	want, err := hex.DecodeString(declared)
+	if err != nil {
+		if want, err = base64.StdEncoding.DecodeString(declared); err != nil {
+			return fmt.Errorf("invalid %s trailer %q", checksumTrailer, declared)
+		}
+	}
+	if got := sum.Sum(nil); !bytes.Equal(got, want) {
+		return fmt.Errorf("%w: %s trailer is %s, body hashes to %x", ErrChecksumMismatch, checksumTrailer, declared, got)
+	}
+	return nil
+}
+
+// preferredExtensions picks the usual extension for types that have several
+var preferredExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"text/plain": ".txt",
+}
+
+// downloadFileName picks the name for a download saved without an explicit
+// path: the Content-Disposition filename if the server sent one, otherwise
+// the last segment of the URL path with its extension replaced by the one
+// matching Content-Type. Directories in either are dropped.
+func downloadFileName(rawURL string, header http.Header, fallbackExt string) string {
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+		if name := baseName(params["filename"]); name != "" {
+			return name
+		}
+	}
+
+	name := "download"
+	if u, err := url.Parse(rawURL); err == nil {
+		if base := baseName(u.Path); base != "" {
+			name = base
+		}
+	}
+	ext := extensionForType(header.Get("Content-Type"), path.Ext(name), fallbackExt)
+	return strings.TrimSuffix(name, path.Ext(name)) + ext
+}
+
+// baseName returns the last element of a slash or backslash separated path,
+// or "" if there is none
+func baseName(p string) string {
+	name := path.Base(strings.ReplaceAll(p, `\`, "/"))
+	if name == "." || name == ".." || name == "/" {
+		return ""
+	}
+	return name
+}
+
+// extensionForType returns the file extension for contentType, with any
+// parameters such as charset ignored, so "image/jpeg; charset=binary" gives
+// .jpg and "image/svg+xml" gives .svg. The current extension is kept when it
+// fits the type, or when the type says nothing about the format. fallback is
+// only used when the Content-Type can't be parsed, or doesn't map to any
+// extension and the URL had none.
+func extensionForType(contentType, current, fallback string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "application/octet-stream" {
+		if current != "" {
+			return current
+		}
+		return fallback
+	}
+
+	exts, _ := mime.ExtensionsByType(mediaType)
+	for _, ext := range exts {
+		if strings.EqualFold(ext, current) {
+			return current
+		}
+	}
+	if ext, ok := preferredExtensions[mediaType]; ok {
+		return ext
+	}
+	if len(exts) > 0 {
+		return exts[0]
+	}
+	if current != "" {
+		return current
+	}
+	// Image types missing from the mime tables still name their format, so
+	// image/x-portable-anymap+xml gives .portable-anymap
+	if typ, sub, _ := strings.Cut(mediaType, "/"); typ == "image" {
+		sub, _, _ = strings.Cut(sub, "+")
+		if sub = strings.TrimPrefix(sub, "x-"); sub != "" {
+			return "." + sub
+		}
+	}
+	return fallback
+}
+
+// resumeSuffix names the sidecar file ResumeDownload keeps the validator in
+const resumeSuffix = ".rushgo-resume"
+
+// ResumeDownload downloads url to destPath, continuing a partial file left by
+// an earlier interrupted call instead of starting over. The resume request
+// carries If-Range with the ETag (or Last-Modified date) of the first response,
+// so if the file changed on the server the full new file is sent and the
+// partial one is replaced rather than corrupted. Without a stored validator, or
+// when the server ignores the range, the download restarts from scratch. With
+// WithRetry set, a connection drop mid-body is resumed the same way. A
+// canceled download returns a *DownloadCanceledError and keeps the partial
+// file so it can be resumed later. Once the body is written, the part on disk
+// and the new range must cover the file's full size exactly; if they leave a
+// gap or overlap, the file is deleted and an error wrapping ErrDownloadGap is
+// returned.
+func (rg *RushGo) ResumeDownload(url, destPath string) (*http.Response, error) {
+	var resp *http.Response
+	err := rg.retryOnTruncation(nil, func() (err error) {
+		resp, err = rg.resumeDownload(url, destPath)
+		return err
+	})
+	return resp, err
+}
+
+func (rg *RushGo) resumeDownload(url, destPath string) (*http.Response, error) {
+	metaPath := destPath + resumeSuffix
+
+	var offset int64
+	validator := ""
+	if info, err := os.Stat(destPath); err == nil {
+		if data, err := os.ReadFile(metaPath); err == nil && len(data) > 0 {
+			offset = info.Size()
+			validator = string(data)
+		}
+	}
+
+	headers := map[string]string{}
+	if offset > 0 {
+		headers["Range"] = fmt.Sprintf("bytes=%d-", offset)
+		headers["If-Range"] = validator
+	}
+
+	resp, err := rg.do("GET", url, nil, &requestOptions{headers: headers})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	var written byteRanges
+	size := resp.ContentLength
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		start, end, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return nil, fmt.Errorf("failed to resume download: unexpected Content-Range %q", resp.Header.Get("Content-Range"))
+		}
+		flags |= os.O_APPEND
+		written.add(0, offset)
+		if size = total; size < 0 {
+			size = end + 1
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// Nothing left to fetch if the partial file already has every byte
+		if _, _, total, ok := parseContentRange(resp.Header.Get("Content-Range")); ok && total == offset {
+			os.Remove(metaPath)
+			return resp, nil
+		}
+		return nil, fmt.Errorf("failed to resume download: status code %d", resp.StatusCode)
+	case resp.StatusCode == http.StatusOK:
+		// The file changed or the server ignored the range, start over
+		flags |= os.O_TRUNC
+	default:
+		return nil, fmt.Errorf("failed to download file: status code %d", resp.StatusCode)
+	}
+
+	if v := resumeValidator(resp.Header); v != "" {
+		if err := os.WriteFile(metaPath, []byte(v), 0o644); err != nil {
+			return nil, err
+		}
+	} else {
+		os.Remove(metaPath)
+	}
+
+	file, err := os.OpenFile(destPath, flags, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	n, err := io.Copy(file, resp.Body)
+	if err != nil {
+		// The partial file and validator stay behind for the next resume
+		if isCanceled(err) {
+			return nil, &DownloadCanceledError{Written: n, Err: err}
+		}
+		return nil, err
+	}
+
+	// A body that ends early without an error, or a range that doesn't line up
+	// with the part already on disk, would otherwise leave a silently corrupt
+	// file, so check the pieces add up to the whole before calling it done
+	start := int64(0)
+	if flags&os.O_APPEND != 0 {
+		start = offset
+	}
+	err = written.add(start, start+n)
+	if err == nil && size >= 0 {
+		err = written.check(size)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(destPath)
+		os.Remove(metaPath)
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+
+	os.Remove(metaPath)
+	return resp, nil
+}
+
+// byteRanges tracks the half-open ranges [start, end) written to a file that
+// is assembled piece by piece, kept sorted and merged
+type byteRanges [][2]int64
+
+// add records [start, end). Overlapping a range already written is an error,
+// since the same bytes would have been written twice.
+func (r *byteRanges) add(start, end int64) error {
+	if start >= end {
+		return nil
+	}
+	i := 0
+	for i < len(*r) && (*r)[i][1] <= start {
+		i++
+	}
+	if i < len(*r) && (*r)[i][0] < end {
+		return fmt.Errorf("bytes %d-%d written twice: %w", start, end-1, ErrDownloadGap)
+	}
+	*r = append(*r, [2]int64{})
+	copy((*r)[i+1:], (*r)[i:])
+	(*r)[i] = [2]int64{start, end}
+
+	// Merge with the neighbours it touches
+	if i+1 < len(*r) && (*r)[i+1][0] == end {
+		(*r)[i][1] = (*r)[i+1][1]
+		*r = append((*r)[:i+1], (*r)[i+2:]...)
+	}
+	if i > 0 && (*r)[i-1][1] == start {
+		(*r)[i-1][1] = (*r)[i][1]
+		*r = append((*r)[:i], (*r)[i+1:]...)
+	}
+	return nil
+}
+
+// check returns an error naming the first gap unless the ranges cover
+// exactly [0, size)
+func (r byteRanges) check(size int64) error {
+	next := int64(0)
+	for _, rng := range r {
+		if rng[0] > next {
+			return fmt.Errorf("bytes %d-%d missing: %w", next, rng[0]-1, ErrDownloadGap)
+		}
+		next = rng[1]
+	}
+	switch {
+	case next < size:
+		return fmt.Errorf("bytes %d-%d missing: %w", next, size-1, ErrDownloadGap)
+	case next > size:
+		return fmt.Errorf("%d bytes written past the end of the %d byte file: %w", next-size, size, ErrDownloadGap)
+	}
+	return nil
+}
+
+// resumeValidator picks the validator to send in If-Range. Weak ETags are not
+// allowed there, so Last-Modified is used instead.
+func resumeValidator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return header.Get("Last-Modified")
+}
+
+// parseContentRange parses "bytes start-end/total" and "bytes */total". Unknown
+// parts are returned as -1.
+func parseContentRange(value string) (start, end, total int64, ok bool) {
+	spec, found := strings.CutPrefix(value, "bytes ")
+	if !found {
+		return 0, 0, 0, false
+	}
+	rangePart, totalPart, found := strings.Cut(spec, "/")
+	if !found {
+		return 0, 0, 0, false
+	}
+
+	total = -1
+	if totalPart != "*" {
+		var err error
+		if total, err = strconv.ParseInt(totalPart, 10, 64); err != nil {
+			return 0, 0, 0, false
+		}
+	}
+	if rangePart == "*" {
+		return -1, -1, total, true
+	}
+
+	startPart, endPart, found := strings.Cut(rangePart, "-")
+	if !found {
+		return 0, 0, 0, false
+	}
+	start, err := strconv.ParseInt(startPart, 10, 64)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	end, err = strconv.ParseInt(endPart, 10, 64)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	return start, end, total, true
+}
@@ -0,0 +1,72 @@
+package rushgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadCanceledMidStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Send half the promised body, then stall until the client gives up
+		w.Header().Set("Content-Length", "2048")
+		w.Write([]byte(strings.Repeat("x", 1024)))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	savePath := filepath.Join(t.TempDir(), "partial.bin")
+	_, err := New(nil).DownloadFileCtx(ctx, srv.URL, &savePath)
+
+	var canceled *DownloadCanceledError
+	if !errors.As(err, &canceled) {
+		t.Fatalf("error = %v, want *DownloadCanceledError", err)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want it to unwrap to context.Canceled", err)
+	}
+	if canceled.Written <= 0 {
+		t.Errorf("Written = %d, want the bytes received before the stall", canceled.Written)
+	}
+	if _, err := os.Stat(savePath); !os.IsNotExist(err) {
+		t.Errorf("partial file still exists: stat error %v", err)
+	}
+}
+
+func TestExtensionForType(t *testing.T) {
+	tests := []struct {
+		contentType, current, fallback string
+		want                           string
+	}{
+		{"image/jpeg", "", ".bin", ".jpg"},
+		{"image/jpeg; charset=binary", "", ".bin", ".jpg"},
+		{"IMAGE/JPEG ; q=1", "", ".bin", ".jpg"},
+		{"image/jpeg", ".jpeg", ".bin", ".jpeg"}, // a matching extension is kept
+		{"image/png", ".jpg", ".bin", ".png"},    // a wrong one is replaced
+		{"image/svg+xml", "", ".bin", ".svg"},
+		{"image/svg+xml; charset=utf-8", "", ".bin", ".svg"},
+		{"image/x-portable-anymap+xml", "", ".bin", ".portable-anymap"},
+		{"application/json; charset=utf-8", "", ".bin", ".json"},
+		{"application/octet-stream", ".zip", ".bin", ".zip"},
+		{"application/octet-stream", "", ".bin", ".bin"},
+		{"application/x-rushgo-unknown", "", ".bin", ".bin"},
+		{"application/x-rushgo-unknown", ".dat", ".bin", ".dat"},
+		{"not a media type", "", ".bin", ".bin"},
+		{"", "", ".jpg", ".jpg"},
+	}
+	for _, tt := range tests {
+		if got := extensionForType(tt.contentType, tt.current, tt.fallback); got != tt.want {
+			t.Errorf("extensionForType(%q, %q, %q) = %q, want %q", tt.contentType, tt.current, tt.fallback, got, tt.want)
+		}
+	}
+}
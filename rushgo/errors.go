@@ -0,0 +1,92 @@
+package rushgo
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// StatusError is returned by helpers that treat a non-2xx response as a failure.
+// It carries the status code and the (possibly truncated) response body.
+type StatusError struct {
+	StatusCode int
+	Status     string
+	Body       []byte
+}
+
+func (e *StatusError) Error() string {
+	if len(e.Body) == 0 {
+		return fmt.Sprintf("unexpected status %s", e.Status)
+	}
+	return fmt.Sprintf("unexpected status %s: %s", e.Status, e.Body)
+}
+
+// maxErrorBody caps how much of a failed response body ends up in a StatusError
+const maxErrorBody = 4096
+
+// newStatusError reads up to maxErrorBody bytes of resp's body into a StatusError.
+// The caller still has to close the body.
+func newStatusError(resp *http.Response) *StatusError {
+	body := make([]byte, maxErrorBody)
+	n, _ := io.ReadFull(resp.Body, body)
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Body:       body[:n],
+	}
+}
+
+// maxMappedBody caps how much of a response body the status error mapper sees
+const maxMappedBody = 1 << 20
+
+// WithStatusErrorMapper translates response statuses into errors returned from
+// the request methods, e.g. 404 into a domain ErrNotFound, so status checks
+// don't have to be repeated at every call site. The mapper gets the status
+// code and up to 1 MiB of the body; returning nil treats the response as a
+// success and hands it back with its body intact. By default it runs for
+// non-2xx responses only, see WithStatusErrorPredicate. It applies to every
+// request, including those the helpers make, so keep mappings to statuses the
+// helpers don't rely on (e.g. 416 for ResumeDownload).
+func (rg *RushGo) WithStatusErrorMapper(mapper func(status int, body []byte) error) *RushGo {
+	rg.statusMapper = mapper
+	return rg
+}
+
+// WithStatusErrorPredicate sets which statuses WithStatusErrorMapper runs for
+func (rg *RushGo) WithStatusErrorPredicate(match func(status int) bool) *RushGo {
+	rg.statusMatch = match
+	return rg
+}
+
+// mapStatus runs the status error mapper on resp. If the mapper returns an
+// error the body is closed.
+func (rg *RushGo) mapStatus(resp *http.Response) (*http.Response, error) {
+	if rg.statusMapper == nil {
+		return resp, nil
+	}
+	match := rg.statusMatch
+	if match == nil {
+		match = func(status int) bool { return status < 200 || status > 299 }
+	}
+	if !match(resp.StatusCode) {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxMappedBody))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if err := rg.statusMapper(resp.StatusCode, body); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	// Put back what the mapper read in front of anything left
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+	return resp, nil
+}
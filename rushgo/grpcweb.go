@@ -0,0 +1,157 @@
+package rushgo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// gRPC-Web frame flags
+const (
+	grpcWebDataFrame    = 0x00
+	grpcWebTrailerFrame = 0x80
+	grpcWebCompressed   = 0x01
+)
+
+// defaultGRPCWebMaxFrame is the largest frame GRPCWebUnary reads when
+// WithMaxBodySize is not set, the same 4 MiB gRPC uses for received messages
+const defaultGRPCWebMaxFrame = 4 << 20
+
+// GRPCStatusError is returned by GRPCWebUnary when the call ends with a
+// non-zero grpc-status
+type GRPCStatusError struct {
+	Code    int // gRPC status code, e.g. 5 for NOT_FOUND
+	Message string
+}
+
+func (e *GRPCStatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("grpc-status %d", e.Code)
+	}
+	return fmt.Sprintf("grpc-status %d: %s", e.Code, e.Message)
+}
+
+// GRPCWebUnary makes a unary gRPC-Web call to url, the full method path such
+// as "https://host/package.Service/Method". request is the serialized
+// protobuf request message and the serialized response message is returned,
+// so any protobuf library works, e.g.
+//
+//	in, _ := proto.Marshal(req)
+//	out, err := rg.GRPCWebUnary(url, in)
+//	proto.Unmarshal(out, resp)
+//
+// The message is sent as a length-prefixed frame with Content-Type
+// application/grpc-web+proto. A grpc-status from the trailer frame, or from
+// the response headers for a trailers-only response, other than 0 is
+// returned as a *GRPCStatusError. Compressed frames are not supported. A
+// frame larger than the WithMaxBodySize limit, or 4 MiB if none is set, fails
+// with an error wrapping ErrBodyTooLarge.
+func (rg *RushGo) GRPCWebUnary(url string, request []byte) ([]byte, error) {
+	frame := make([]byte, 5+len(request))
+	frame[0] = grpcWebDataFrame
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(request)))
+	copy(frame[5:], request)
+
+	resp, err := rg.do("POST", url, frame, &requestOptions{headers: map[string]string{
+		"Content-Type": "application/grpc-web+proto",
+		"Accept":       "application/grpc-web+proto",
+		"X-Grpc-Web":   "1",
+	}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newStatusError(resp)
+	}
+
+	maxFrame := int64(defaultGRPCWebMaxFrame)
+	if rg.maxBodySize > 0 {
+		maxFrame = rg.maxBodySize
+	}
+
+	var message []byte
+	trailer := textproto.MIMEHeader{}
+	gotMessage := false
+	for {
+		var header [5]byte
+		if _, err := io.ReadFull(resp.Body, header[:]); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read gRPC-Web frame: %w", err)
+		}
+		size := int64(binary.BigEndian.Uint32(header[1:5]))
+		if size > maxFrame {
+			return nil, fmt.Errorf("failed to read gRPC-Web frame: %d bytes exceeds %d: %w", size, maxFrame, ErrBodyTooLarge)
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(resp.Body, payload); err != nil {
+			return nil, fmt.Errorf("failed to read gRPC-Web frame: %w", err)
+		}
+
+		switch {
+		case header[0]&grpcWebTrailerFrame != 0:
+			if trailer, err = parseGRPCWebTrailer(payload); err != nil {
+				return nil, err
+			}
+		case header[0]&grpcWebCompressed != 0:
+			return nil, errors.New("failed to read gRPC-Web frame: compressed messages are not supported")
+		case gotMessage:
+			return nil, errors.New("failed to read gRPC-Web response: more than one message in a unary response")
+		default:
+			message, gotMessage = payload, true
+		}
+	}
+
+	status := trailer.Get("Grpc-Status")
+	statusMessage := trailer.Get("Grpc-Message")
+	if status == "" {
+		// Trailers-only responses carry the status in the headers
+		status = resp.Header.Get("Grpc-Status")
+		statusMessage = resp.Header.Get("Grpc-Message")
+	}
+	if status == "" {
+		return nil, errors.New("failed to read gRPC-Web response: no grpc-status")
+	}
+	code, err := strconv.Atoi(strings.TrimSpace(status))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gRPC-Web response: invalid grpc-status %q", status)
+	}
+	if code != 0 {
+		return nil, &GRPCStatusError{Code: code, Message: unescapeGRPCMessage(statusMessage)}
+	}
+	if !gotMessage {
+		return nil, errors.New("failed to read gRPC-Web response: no message")
+	}
+	return message, nil
+}
+
+// parseGRPCWebTrailer parses the HTTP/1-style header block of a trailer frame
+func parseGRPCWebTrailer(payload []byte) (textproto.MIMEHeader, error) {
+	if !bytes.HasSuffix(payload, []byte("\r\n")) {
+		payload = append(payload, '\r', '\n')
+	}
+	payload = append(payload, '\r', '\n')
+	trailer, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(payload))).ReadMIMEHeader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse gRPC-Web trailer: %w", err)
+	}
+	return trailer, nil
+}
+
+// unescapeGRPCMessage decodes the percent-encoding of grpc-message
+func unescapeGRPCMessage(message string) string {
+	if decoded, err := url.PathUnescape(message); err == nil {
+		return decoded
+	}
+	return message
+}
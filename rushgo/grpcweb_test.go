@@ -0,0 +1,49 @@
+package rushgo
+
+import (
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// grpcWebFrame builds a length-prefixed gRPC-Web frame
+func grpcWebFrame(flags byte, payload []byte) []byte {
+	frame := make([]byte, 5+len(payload))
+	frame[0] = flags
+	binary.BigEndian.PutUint32(frame[1:5], uint32(len(payload)))
+	copy(frame[5:], payload)
+	return frame
+}
+
+func TestGRPCWebUnary(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/grpc-web+proto")
+		if r.URL.Path == "/huge" {
+			// Claims a 4 GiB message without sending it
+			w.Write([]byte{grpcWebDataFrame, 0xff, 0xff, 0xff, 0xff})
+			return
+		}
+		w.Write(grpcWebFrame(grpcWebDataFrame, []byte("reply")))
+		w.Write(grpcWebFrame(grpcWebTrailerFrame, []byte("grpc-status: 0\r\n")))
+	}))
+	defer srv.Close()
+
+	rg := New(nil)
+	out, err := rg.GRPCWebUnary(srv.URL+"/pkg.Service/Method", []byte("request"))
+	if err != nil {
+		t.Fatalf("GRPCWebUnary: %v", err)
+	}
+	if string(out) != "reply" {
+		t.Errorf("message = %q, want reply", out)
+	}
+
+	if _, err := rg.GRPCWebUnary(srv.URL+"/huge", nil); !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("oversized frame error = %v, want ErrBodyTooLarge", err)
+	}
+	rg.WithMaxBodySize(3)
+	if _, err := rg.GRPCWebUnary(srv.URL+"/pkg.Service/Method", nil); !errors.Is(err, ErrBodyTooLarge) {
+		t.Errorf("frame over WithMaxBodySize error = %v, want ErrBodyTooLarge", err)
+	}
+}
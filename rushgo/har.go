@@ -0,0 +1,137 @@
+package rushgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+)
+
+// HAREntry is one request/response pair of a HAR 1.2 log, as exported by
+// browser developer tools and proxies. Only the fields Replay needs are
+// decoded.
+type HAREntry struct {
+	StartedDateTime string     `json:"startedDateTime"`
+	Request         HARRequest `json:"request"`
+}
+
+// HARRequest is the request part of a HAREntry
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []HARNameValue `json:"headers"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+}
+
+// HARNameValue is a header, cookie or form parameter in a HAR log
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// HARPostData is the body of a recorded request, given either as text or as
+// form parameters
+type HARPostData struct {
+	MimeType string         `json:"mimeType"`
+	Text     string         `json:"text"`
+	Params   []HARNameValue `json:"params,omitempty"`
+}
+
+// ParseHAR returns the entries of a HAR file
+func ParseHAR(data []byte) ([]HAREntry, error) {
+	var har struct {
+		Log struct {
+			Entries []HAREntry `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(data, &har); err != nil {
+		return nil, fmt.Errorf("failed to parse HAR: %w", err)
+	}
+	return har.Log.Entries, nil
+}
+
+// harSkippedHeaders are not replayed: hop-by-hop headers only meant for the
+// original connection, and headers the transport sets itself
+var harSkippedHeaders = map[string]bool{
+	"Connection":          true,
+	"Keep-Alive":          true,
+	"Proxy-Authenticate":  true,
+	"Proxy-Authorization": true,
+	"Proxy-Connection":    true,
+	"Te":                  true,
+	"Trailer":             true,
+	"Transfer-Encoding":   true,
+	"Upgrade":             true,
+	"Host":                true,
+	"Content-Length":      true,
+}
+
+// Replay sends the request recorded in entry again through this client, with
+// its original method, URL, headers and body. The client's default headers
+// are applied first, so the recorded ones win where both are set.
+// Hop-by-hop headers, including any the recorded Connection header lists, and
+// HTTP/2 pseudo-headers such as :authority are left out, and codings RushGo
+// can't decode, such as br, are dropped from Accept-Encoding.
+func (rg *RushGo) Replay(entry HAREntry) (*http.Response, error) {
+	req := entry.Request
+	if req.Method == "" || req.URL == "" {
+		return nil, fmt.Errorf("failed to replay request: HAR entry has no method or URL")
+	}
+
+	skip := map[string]bool{}
+	for _, h := range req.Headers {
+		if strings.EqualFold(h.Name, "Connection") {
+			for _, name := range strings.Split(h.Value, ",") {
+				skip[textproto.CanonicalMIMEHeaderKey(strings.TrimSpace(name))] = true
+			}
+		}
+	}
+
+	headers := map[string]string{}
+	for _, h := range req.Headers {
+		name := textproto.CanonicalMIMEHeaderKey(h.Name)
+		if strings.HasPrefix(name, ":") || harSkippedHeaders[name] || skip[name] {
+			continue
+		}
+		if existing, ok := headers[name]; ok {
+			// Repeated headers are recorded one per entry
+			separator := ", "
+			if name == "Cookie" {
+				separator = "; "
+			}
+			headers[name] = existing + separator + h.Value
+			continue
+		}
+		headers[name] = h.Value
+	}
+	if encoding, ok := headers["Accept-Encoding"]; ok {
+		// Browsers offer codings such as br that can't be decoded here
+		if encoding = supportedAcceptEncoding(encoding); encoding != "" {
+			headers["Accept-Encoding"] = encoding
+		} else {
+			delete(headers, "Accept-Encoding")
+		}
+	}
+
+	var body []byte
+	if data := req.PostData; data != nil {
+		switch {
+		case data.Text != "":
+			body = []byte(data.Text)
+		case len(data.Params) > 0:
+			form := url.Values{}
+			for _, p := range data.Params {
+				form.Add(p.Name, p.Value)
+			}
+			body = []byte(form.Encode())
+		}
+		if _, ok := headers["Content-Type"]; !ok && data.MimeType != "" {
+			headers["Content-Type"] = data.MimeType
+		}
+	}
+
+	return rg.do(strings.ToUpper(req.Method), req.URL, body, &requestOptions{headers: headers})
+}
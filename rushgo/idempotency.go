@@ -0,0 +1,161 @@
+package rushgo
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// coalescer merges requests with the same idempotency key into one call
+type coalescer struct {
+	keyFunc func(*http.Request) string
+	window  time.Duration
+
+	mu    sync.Mutex
+	calls map[string]*coalescedCall
+}
+
+type coalescedCall struct {
+	done     chan struct{}
+	resp     *http.Response // body already read into body
+	body     []byte
+	err      error
+	tooLarge bool // the body was over the limit and went to the first caller only
+}
+
+// defaultCoalesceLimit is the largest body shared between coalesced requests
+// when WithMaxBodySize is not set
+const defaultCoalesceLimit = 4 << 20
+
+// WithIdempotencyKey coalesces requests that keyFunc maps to the same
+// non-empty key, typically the Idempotency-Key header: while one of them is in
+// flight the others wait for it instead of hitting the server, and all callers
+// get the same result with their own copy of the body. With
+// WithIdempotencyWindow the result is also reused for that long afterwards,
+// unless it is an error or a status WithRetry retries, such as a 503.
+// Since the shared body is held in memory, only bodies up to the
+// WithMaxBodySize limit, or 4 MiB if none is set, are shared. A larger one is
+// streamed to the request that fetched it, and the waiting requests are sent
+// on their own.
+func (rg *RushGo) WithIdempotencyKey(keyFunc func(*http.Request) string) *RushGo {
+	window := time.Duration(0)
+	if rg.coalescer != nil {
+		window = rg.coalescer.window
+	}
+	rg.coalescer = &coalescer{
+		keyFunc: keyFunc,
+		window:  window,
+		calls:   make(map[string]*coalescedCall),
+	}
+	return rg
+}
+
+// WithIdempotencyWindow keeps the result of a coalesced request for window
+// after it completes, so requests with the same key arriving shortly after
+// reuse it too. The default of zero only coalesces concurrent requests.
+func (rg *RushGo) WithIdempotencyWindow(window time.Duration) *RushGo {
+	if rg.coalescer == nil {
+		rg.WithIdempotencyKey(func(*http.Request) string { return "" })
+	}
+	rg.coalescer.window = window
+	return rg
+}
+
+// coalesceLimit returns the largest body the coalescer buffers
+func (rg *RushGo) coalesceLimit() int64 {
+	if rg.maxBodySize > 0 {
+		return rg.maxBodySize
+	}
+	return defaultCoalesceLimit
+}
+
+// do sends req through send unless a request with the same key is already in
+// flight (or finished within the window), in which case its result is shared.
+// Bodies over limit are not shared.
+func (c *coalescer) do(req *http.Request, send func(*http.Request) (*http.Response, error), limit int64) (*http.Response, error) {
+	key := c.keyFunc(req)
+	if key == "" {
+		return send(req)
+	}
+
+	c.mu.Lock()
+	if call, ok := c.calls[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-req.Context().Done():
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, req.Context().Err()
+		}
+		if call.tooLarge {
+			return send(req)
+		}
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return call.result(req)
+	}
+	call := &coalescedCall{done: make(chan struct{})}
+	c.calls[key] = call
+	c.mu.Unlock()
+
+	resp, err := send(req)
+	if err == nil {
+		call.body, err = io.ReadAll(io.LimitReader(resp.Body, limit+1))
+		switch {
+		case err != nil:
+			resp.Body.Close()
+		case int64(len(call.body)) > limit:
+			// Too big to hold for everyone, so hand this caller the stream
+			call.tooLarge = true
+			resp.Body = &prefixedBody{Reader: io.MultiReader(bytes.NewReader(call.body), resp.Body), Closer: resp.Body}
+			call.body = nil
+		default:
+			resp.Body.Close()
+			call.resp = resp
+		}
+	}
+	call.err = err
+	close(call.done)
+
+	forget := func() {
+		c.mu.Lock()
+		if c.calls[key] == call {
+			delete(c.calls, key)
+		}
+		c.mu.Unlock()
+	}
+	// A retryable status is dropped at once so the retry reaches the server
+	if c.window > 0 && err == nil && !call.tooLarge && !isRetryableStatus(call.resp.StatusCode) {
+		time.AfterFunc(c.window, forget)
+	} else {
+		forget()
+	}
+
+	if call.tooLarge {
+		return resp, nil
+	}
+	return call.result(req)
+}
+
+// prefixedBody is a response body whose first bytes were already read
+type prefixedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// result gives each caller its own response with a fresh body reader
+func (call *coalescedCall) result(req *http.Request) (*http.Response, error) {
+	if call.err != nil {
+		return nil, call.err
+	}
+	resp := *call.resp
+	resp.Header = call.resp.Header.Clone()
+	resp.Body = io.NopCloser(bytes.NewReader(call.body))
+	resp.Request = req
+	return &resp, nil
+}
@@ -0,0 +1,98 @@
+package rushgo
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestIdempotencyKeyBodyLimit(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if r.URL.Path == "/large" {
+			w.Write([]byte(strings.Repeat("x", 100)))
+			return
+		}
+		w.Write([]byte("small"))
+	}))
+	defer srv.Close()
+
+	rg := New(nil).
+		WithMaxBodySize(64).
+		WithIdempotencyKey(func(req *http.Request) string { return req.URL.Path }).
+		WithIdempotencyWindow(time.Minute)
+
+	tests := []struct {
+		path     string
+		wantBody string
+		wantHits int32
+	}{
+		{"/small", "small", 1},                  // the second call reuses the first
+		{"/large", strings.Repeat("x", 100), 2}, // too big to share, so both are sent
+	}
+	for _, tt := range tests {
+		atomic.StoreInt32(&hits, 0)
+		for i := 0; i < 2; i++ {
+			resp, err := rg.Get(srv.URL + tt.path)
+			if err != nil {
+				t.Fatalf("%s: Get: %v", tt.path, err)
+			}
+			body, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				t.Fatalf("%s: read body: %v", tt.path, err)
+			}
+			if string(body) != tt.wantBody {
+				t.Errorf("%s: body = %q, want %q", tt.path, body, tt.wantBody)
+			}
+		}
+		if got := atomic.LoadInt32(&hits); got != tt.wantHits {
+			t.Errorf("%s: server hit %d times, want %d", tt.path, got, tt.wantHits)
+		}
+	}
+}
+
+func TestIdempotencyWindowRetriesRetryableStatus(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	rg := New(nil).
+		WithRetry(2, time.Millisecond).
+		WithIdempotencyKey(func(req *http.Request) string { return req.URL.Path }).
+		WithIdempotencyWindow(time.Minute)
+
+	resp, err := rg.Get(srv.URL + "/pay")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Fatalf("got %d %q, want 200 \"ok\"", resp.StatusCode, body)
+	}
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Fatalf("server hit %d times, want 2", got)
+	}
+
+	// The 200 is kept for the window
+	resp, err = rg.Get(srv.URL + "/pay")
+	if err != nil {
+		t.Fatalf("second Get: %v", err)
+	}
+	resp.Body.Close()
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Fatalf("server hit %d times after the cached call, want 2", got)
+	}
+}
@@ -0,0 +1,137 @@
+package rushgo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// ErrClientClosed is returned for requests made after Shutdown has been called
+var ErrClientClosed = errors.New("client is shut down")
+
+// inFlight tracks the requests currently being sent or having their body read
+type inFlight struct {
+	mu     sync.Mutex
+	count  int
+	idle   chan struct{} // closed when count drops back to zero
+	closed bool          // set by Shutdown, no new requests are accepted
+}
+
+func (f *inFlight) add() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		return ErrClientClosed
+	}
+	if f.count == 0 {
+		f.idle = make(chan struct{})
+	}
+	f.count++
+	return nil
+}
+
+func (f *inFlight) done() {
+	f.mu.Lock()
+	f.count--
+	if f.count == 0 {
+		close(f.idle)
+	}
+	f.mu.Unlock()
+}
+
+// inFlightBody is a response body that counts as in flight until it is read
+// to the end, fails or is closed
+type inFlightBody struct {
+	io.ReadCloser
+	once sync.Once
+	done func()
+}
+
+func (b *inFlightBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil {
+		b.once.Do(b.done)
+	}
+	return n, err
+}
+
+func (b *inFlightBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.done)
+	return err
+}
+
+// InFlight returns the number of requests the client is currently processing,
+// including responses whose bodies are still being read
+func (rg *RushGo) InFlight() int {
+	rg.inFlight.mu.Lock()
+	defer rg.inFlight.mu.Unlock()
+	return rg.inFlight.count
+}
+
+// Drain blocks until there are no requests in flight, bodies included, or ctx
+// is done, in which case it returns the context error
+func (rg *RushGo) Drain(ctx context.Context) error {
+	rg.inFlight.mu.Lock()
+	if rg.inFlight.count == 0 {
+		rg.inFlight.mu.Unlock()
+		return nil
+	}
+	idle := rg.inFlight.idle
+	rg.inFlight.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown stops the client from accepting new requests, which then fail with
+// ErrClientClosed, waits for in-flight requests to finish and closes idle
+// connections. A request is finished once its response body has been read to
+// the end or closed, so a body that is never closed holds Shutdown until ctx
+// expires. If ctx expires first the context error is returned and the
+// outstanding requests are left running.
+func (rg *RushGo) Shutdown(ctx context.Context) error {
+	rg.inFlight.mu.Lock()
+	rg.inFlight.closed = true
+	rg.inFlight.mu.Unlock()
+
+	if err := rg.Drain(ctx); err != nil {
+		return err
+	}
+	rg.client.CloseIdleConnections()
+	return nil
+}
+
+// rootContext is the client-level context every request derives from; CancelAll
+// cancels it and replaces it with a fresh one
+type rootContext struct {
+	mu     sync.Mutex
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (r *rootContext) get() context.Context {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ctx == nil {
+		r.ctx, r.cancel = context.WithCancel(context.Background())
+	}
+	return r.ctx
+}
+
+// CancelAll aborts every request currently in flight, including responses
+// whose bodies are still being read; they fail with context.Canceled.
+// Requests started afterwards are unaffected.
+func (rg *RushGo) CancelAll() {
+	rg.root.mu.Lock()
+	defer rg.root.mu.Unlock()
+	if rg.root.cancel != nil {
+		rg.root.cancel()
+	}
+	rg.root.ctx, rg.root.cancel = context.WithCancel(context.Background())
+}
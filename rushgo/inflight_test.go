@@ -0,0 +1,63 @@
+package rushgo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestShutdownWaitsForStreamingBody(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("first "))
+		w.(http.Flusher).Flush()
+		<-release
+		w.Write([]byte("last"))
+	}))
+	defer srv.Close()
+
+	rg := New(nil)
+	resp, err := rg.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if got := rg.InFlight(); got != 1 {
+		t.Fatalf("InFlight = %d with the body unread, want 1", got)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- rg.Shutdown(context.Background()) }()
+
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown returned while the body was streaming: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if _, err := rg.Get(srv.URL); !errors.Is(err, ErrClientClosed) {
+		t.Fatalf("Get during Shutdown: err = %v, want ErrClientClosed", err)
+	}
+
+	close(release)
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || string(body) != "first last" {
+		t.Fatalf("body = %q, %v, want \"first last\"", body, err)
+	}
+
+	// Reading to EOF finishes the request even before Close
+	select {
+	case err := <-shutdown:
+		if err != nil {
+			t.Fatalf("Shutdown: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Shutdown did not return after the body was read")
+	}
+	resp.Body.Close()
+	if got := rg.InFlight(); got != 0 {
+		t.Fatalf("InFlight = %d after Close, want 0", got)
+	}
+}
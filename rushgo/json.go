@@ -0,0 +1,174 @@
+package rushgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// WithJSONUseNumber makes the JSON decode helpers keep numbers as json.Number
+// instead of float64 when decoding into interface{}, so large integers such as
+// 64-bit IDs keep their precision. It is off by default.
+func (rg *RushGo) WithJSONUseNumber() *RushGo {
+	rg.jsonUseNumber = true
+	return rg
+}
+
+// WithJSONCodec replaces encoding/json in every JSON helper (PostJSON,
+// PutJSON, PatchJSON, GetJSON, Decode, DecodeJSON and GetJSONArray), e.g. with
+// a faster library such as jsoniter or sonic, without RushGo depending on it.
+// GetJSONArray still splits the array with encoding/json and hands each
+// element to unmarshal. WithJSONUseNumber only applies to encoding/json.
+func (rg *RushGo) WithJSONCodec(marshal func(interface{}) ([]byte, error), unmarshal func([]byte, interface{}) error) *RushGo {
+	rg.jsonMarshal = marshal
+	rg.jsonUnmarshal = unmarshal
+	return rg
+}
+
+// newJSONDecoder returns a decoder for r configured with the client's JSON
+// settings. rg may be nil, e.g. for a response not made by RushGo.
+func (rg *RushGo) newJSONDecoder(r io.Reader) *json.Decoder {
+	decoder := json.NewDecoder(r)
+	if rg != nil && rg.jsonUseNumber {
+		decoder.UseNumber()
+	}
+	return decoder
+}
+
+// marshalJSON encodes v with the client's codec
+func (rg *RushGo) marshalJSON(v interface{}) ([]byte, error) {
+	if rg.jsonMarshal != nil {
+		return rg.jsonMarshal(v)
+	}
+	return json.Marshal(v)
+}
+
+// decodeJSON decodes one JSON value from r into v with the client's codec.
+// rg may be nil, like for newJSONDecoder.
+func (rg *RushGo) decodeJSON(r io.Reader, v interface{}) error {
+	if rg == nil || rg.jsonUnmarshal == nil {
+		return rg.newJSONDecoder(r).Decode(v)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return rg.jsonUnmarshal(data, v)
+}
+
+// WithRequestSchema sets a validator that PostJSON, PutJSON and PatchJSON run
+// on the marshaled body before sending, e.g. a JSON Schema check. If it returns
+// an error the request is not sent and the error is returned.
+func (rg *RushGo) WithRequestSchema(validate func([]byte) error) *RushGo {
+	rg.requestSchema = validate
+	return rg
+}
+
+// PostJSON marshals v to JSON and POSTs it with Content-Type: application/json
+func (rg *RushGo) PostJSON(url string, v interface{}) (*http.Response, error) {
+	return rg.sendJSON(nil, "POST", url, v)
+}
+
+// PutJSON marshals v to JSON and PUTs it with Content-Type: application/json
+func (rg *RushGo) PutJSON(url string, v interface{}) (*http.Response, error) {
+	return rg.sendJSON(nil, "PUT", url, v)
+}
+
+// GetJSON makes a GET request and decodes the JSON response into v, see DecodeJSON
+func (rg *RushGo) GetJSON(url string, v interface{}) error {
+	return rg.getJSON(nil, url, v)
+}
+
+// getJSON is GetJSON bound to ctx, nil for none
+func (rg *RushGo) getJSON(ctx context.Context, url string, v interface{}) error {
+	resp, err := rg.do("GET", url, nil, &requestOptions{ctx: ctx, headers: map[string]string{"Accept": "application/json"}})
+	if err != nil {
+		return err
+	}
+	return DecodeJSON(resp, v)
+}
+
+// PatchJSON marshals v to JSON and PATCHes it with Content-Type: application/json
+func (rg *RushGo) PatchJSON(url string, v interface{}) (*http.Response, error) {
+	return rg.sendJSON(nil, "PATCH", url, v)
+}
+
+// sendJSON marshals and validates v, then sends it with a JSON Content-Type
+// that applies to this request only. ctx may be nil.
+func (rg *RushGo) sendJSON(ctx context.Context, method, url string, v interface{}) (*http.Response, error) {
+	body, err := rg.marshalJSON(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON body: %w", err)
+	}
+
+	if rg.requestSchema != nil {
+		if err := rg.requestSchema(body); err != nil {
+			return nil, fmt.Errorf("request body failed validation: %w", err)
+		}
+	}
+
+	return rg.do(method, url, body, &requestOptions{ctx: ctx, headers: map[string]string{"Content-Type": "application/json"}})
+}
+
+// GetJSONArray makes a GET request and decodes a top-level JSON array one
+// element at a time, passing each to onItem, so huge arrays never have to fit
+// in memory. It stops at the first error from onItem or when the request is
+// cancelled, and returns an error if the body is not a JSON array.
+func GetJSONArray[T any](rg *RushGo, url string, onItem func(T) error) error {
+	return getJSONArray(nil, rg, url, onItem)
+}
+
+// getJSONArray is GetJSONArray bound to ctx, nil for none
+func getJSONArray[T any](ctx context.Context, rg *RushGo, url string, onItem func(T) error) error {
+	resp, err := rg.do("GET", url, nil, &requestOptions{ctx: ctx})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newStatusError(resp)
+	}
+
+	decoder := rg.newJSONDecoder(resp.Body)
+	token, err := decoder.Token()
+	if err != nil {
+		return fmt.Errorf("failed to decode JSON array: %w", err)
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("failed to decode JSON array: top-level value starts with %v, not [", token)
+	}
+
+	ctx = resp.Request.Context()
+	for decoder.More() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var item T
+		if rg.jsonUnmarshal != nil {
+			var raw json.RawMessage
+			if err = decoder.Decode(&raw); err == nil {
+				err = rg.jsonUnmarshal(raw, &item)
+			}
+		} else {
+			err = decoder.Decode(&item)
+		}
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return fmt.Errorf("failed to decode JSON array element: %w", err)
+		}
+		if err := onItem(item); err != nil {
+			return err
+		}
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return fmt.Errorf("failed to decode JSON array: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+package rushgo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// JSONBinaryOption configures PostJSONWithBinary
+type JSONBinaryOption func(*jsonBinaryConfig)
+
+type jsonBinaryConfig struct {
+	rawThreshold int // []byte fields of at least this size go in their own part, 0 = never
+}
+
+// WithRawBinaryFields makes PostJSONWithBinary send every top-level []byte
+// field of threshold bytes or more as a raw multipart part instead of base64
+// inside the JSON, saving the third of extra size base64 adds. Smaller fields
+// stay in the JSON. A threshold of 0 or less turns this off.
+func WithRawBinaryFields(threshold int) JSONBinaryOption {
+	return func(cfg *jsonBinaryConfig) {
+		cfg.rawThreshold = threshold
+	}
+}
+
+// PostJSONWithBinary POSTs v as JSON, with []byte fields base64-encoded as
+// encoding/json does. With WithRawBinaryFields and at least one []byte field
+// over the threshold, the body becomes multipart/form-data instead: a "json"
+// part (application/json) with the other fields, then one
+// application/octet-stream file part per large field, with the field's JSON
+// key as both name and filename. If no field reaches the threshold a plain
+// JSON body is sent.
+func (rg *RushGo) PostJSONWithBinary(url string, v interface{}, opts ...JSONBinaryOption) (*http.Response, error) {
+	cfg := &jsonBinaryConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	binary := largeBinaryFields(v, cfg.rawThreshold)
+	if len(binary) == 0 {
+		return rg.sendJSON(nil, "POST", url, v)
+	}
+
+	body, err := rg.marshalJSON(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON body: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON body: %w", err)
+	}
+	for name := range binary {
+		delete(fields, name)
+	}
+	if body, err = json.Marshal(fields); err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON body: %w", err)
+	}
+	if rg.requestSchema != nil {
+		if err := rg.requestSchema(body); err != nil {
+			return nil, fmt.Errorf("request body failed validation: %w", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	part, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Disposition": {`form-data; name="json"`},
+		"Content-Type":        {"application/json"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	part.Write(body)
+
+	names := make([]string, 0, len(binary))
+	for name := range binary {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Disposition": {fmt.Sprintf(`form-data; name="%s"; filename="%s"`, quoteEscaper.Replace(name), quoteEscaper.Replace(name))},
+			"Content-Type":        {"application/octet-stream"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		part.Write(binary[name])
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return rg.do("POST", url, buf.Bytes(), &requestOptions{headers: map[string]string{"Content-Type": writer.FormDataContentType()}})
+}
+
+// largeBinaryFields returns the top-level []byte fields of the struct v (or
+// pointer to one) that are at least threshold bytes, keyed by JSON name
+func largeBinaryFields(v interface{}, threshold int) map[string][]byte {
+	if threshold <= 0 {
+		return nil
+	}
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields := map[string][]byte{}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() || field.Type != reflect.TypeOf([]byte(nil)) {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		if data := value.Field(i).Bytes(); len(data) >= threshold {
+			fields[name] = data
+		}
+	}
+	return fields
+}
@@ -0,0 +1,111 @@
+package rushgo
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// EmptyFieldsError is returned by PostJSONStrict when required fields of the
+// payload are at their zero value
+type EmptyFieldsError struct {
+	Fields []string // JSON names, nested fields as "parent.child"
+}
+
+func (e *EmptyFieldsError) Error() string {
+	return "request body failed validation: required fields are empty: " + strings.Join(e.Fields, ", ")
+}
+
+// PostJSONStrict is PostJSON that first checks no required field of payload,
+// a struct or pointer to one, is at its zero value, so a struct that was
+// never filled in isn't sent. A field is required if it is exported, not a
+// pointer and has no omitempty or "-" in its json tag. The `rushgo:"required"`
+// tag makes any field required, including pointers (nil is empty) and
+// omitempty fields, and `rushgo:"optional"` exempts one, e.g. a bool whose
+// false is meaningful. Struct fields are checked field by field, types with no
+// exported fields such as time.Time as a whole. The request is not sent if
+// fields are empty and an *EmptyFieldsError naming all of them is returned.
+// Payloads that aren't structs are sent unchecked.
+func (rg *RushGo) PostJSONStrict(url string, payload interface{}) (*http.Response, error) {
+	value := reflect.ValueOf(payload)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil, fmt.Errorf("request body failed validation: payload is a nil %T", payload)
+		}
+		value = value.Elem()
+	}
+	if value.Kind() == reflect.Struct {
+		if empty := emptyRequiredFields(value, ""); len(empty) > 0 {
+			return nil, &EmptyFieldsError{Fields: empty}
+		}
+	}
+	return rg.sendJSON(nil, "POST", url, payload)
+}
+
+// emptyRequiredFields returns the JSON names of the required fields of the
+// struct value that are zero, prefixed with prefix
+func emptyRequiredFields(value reflect.Value, prefix string) []string {
+	var empty []string
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		fieldValue := value.Field(i)
+
+		name, omitEmpty := field.Name, false
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName, opts, _ := strings.Cut(tag, ",")
+			if tagName == "-" && opts == "" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+			omitEmpty = strings.Contains(","+opts+",", ",omitempty,")
+		}
+
+		// Embedded structs are flattened into the parent object
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && !hasJSONName(field) {
+			empty = append(empty, emptyRequiredFields(fieldValue, prefix)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		switch field.Tag.Get("rushgo") {
+		case "optional":
+			continue
+		case "required":
+		default:
+			if omitEmpty || field.Type.Kind() == reflect.Ptr {
+				continue
+			}
+		}
+
+		if fieldValue.Kind() == reflect.Struct && hasExportedFields(fieldValue.Type()) {
+			empty = append(empty, emptyRequiredFields(fieldValue, prefix+name+".")...)
+			continue
+		}
+		if fieldValue.IsZero() {
+			empty = append(empty, prefix+name)
+		}
+	}
+	return empty
+}
+
+// hasJSONName reports whether an embedded field is given its own JSON key
+func hasJSONName(field reflect.StructField) bool {
+	tagName, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	return tagName != ""
+}
+
+// hasExportedFields reports whether encoding/json would encode the struct
+// type t field by field
+func hasExportedFields(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package rushgo
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// WithRequestLogging calls fn after every request the client sends, once the
+// response headers have arrived or the request has failed, with status 0 for
+// a failure. duration is the wall-clock time spent in http.Client.Do, so each
+// retry, redirect chain and reauth is logged on its own and rate limit waits
+// are not counted. Bodies are never passed to fn. A URL with user info has
+// the password masked.
+func (rg *RushGo) WithRequestLogging(fn func(method, url string, status int, duration time.Duration)) *RushGo {
+	if fn == nil {
+		rg.requestLog = nil
+		return rg
+	}
+	rg.requestLog = func(req *http.Request, status int, duration time.Duration, _ error) {
+		fn(req.Method, req.URL.Redacted(), status, duration)
+	}
+	return rg
+}
+
+// logDurationPrecision is what WithLogger rounds durations to
+const logDurationPrecision = 100 * time.Microsecond
+
+// WithLogger writes a line per request to logger in the form
+//
+//	GET https://example.com/ 200 153.2ms
+//
+// with the error in place of the status for a failed request and any tags
+// set with RequestBuilder.Tag at the end. Durations are rounded to 0.1ms. See
+// WithRequestLogging for what is measured.
+func (rg *RushGo) WithLogger(logger *log.Logger) *RushGo {
+	if logger == nil {
+		rg.requestLog = nil
+		return rg
+	}
+	rg.requestLog = func(req *http.Request, status int, duration time.Duration, err error) {
+		duration = duration.Round(logDurationPrecision)
+		line := fmt.Sprintf("%s %s %d %s", req.Method, req.URL.Redacted(), status, duration)
+		if err != nil {
+			line = fmt.Sprintf("%s %s error: %v %s", req.Method, req.URL.Redacted(), err, duration)
+		}
+		if meta := metaFromContext(req.Context()); meta != nil && len(meta.Tags) > 0 {
+			line += fmt.Sprintf(" %v", meta.Tags)
+		}
+		logger.Print(line)
+	}
+	return rg
+}
+
+// logRequest passes one finished round trip to the request logger
+func (rg *RushGo) logRequest(req *http.Request, resp *http.Response, err error, duration time.Duration) {
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	rg.requestLog(req, status, duration, err)
+}
@@ -0,0 +1,39 @@
+package rushgo
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithLoggerRoundsDuration(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	rg := New(nil).WithLogger(log.New(&buf, "", 0))
+	resp, err := rg.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+
+	line := strings.TrimSpace(buf.String())
+	prefix := "GET " + srv.URL + "/ 200 "
+	if !strings.HasPrefix(line, prefix) {
+		t.Fatalf("line = %q, want prefix %q", line, prefix)
+	}
+	duration, err := time.ParseDuration(strings.TrimPrefix(line, prefix))
+	if err != nil {
+		t.Fatalf("line = %q: bad duration: %v", line, err)
+	}
+	if duration%logDurationPrecision != 0 {
+		t.Errorf("duration %v is not rounded to %v", duration, logDurationPrecision)
+	}
+}
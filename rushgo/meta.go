@@ -0,0 +1,36 @@
+package rushgo
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// RequestMeta describes how a response was obtained
+type RequestMeta struct {
+	Attempts int               // requests sent, counting retries, reauth and failover
+	Duration time.Duration     // total time spent, including waits between attempts
+	Cached   bool              // the response was replayed from a cassette
+	Protocol string            // e.g. "HTTP/1.1", "HTTP/2.0" or "HTTP/3.0"
+	Proxy    string            // proxy from WithProxyPool the last attempt used, password redacted
+	Tags     map[string]string // labels set with RequestBuilder.Tag, nil if none
+}
+
+type metaKey struct{}
+
+// Meta returns the metadata for a response made through RushGo, or the zero
+// value for any other response
+func Meta(resp *http.Response) RequestMeta {
+	if resp == nil || resp.Request == nil {
+		return RequestMeta{}
+	}
+	if meta := metaFromContext(resp.Request.Context()); meta != nil {
+		return *meta
+	}
+	return RequestMeta{}
+}
+
+func metaFromContext(ctx context.Context) *RequestMeta {
+	meta, _ := ctx.Value(metaKey{}).(*RequestMeta)
+	return meta
+}
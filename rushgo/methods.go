@@ -2,15 +2,20 @@ package rushgo
 
 import (
 	"bytes"
+	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
 	"net/url"
 	"os"
-	"path"
 	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -27,8 +32,59 @@ type Config struct {
 // RushGo struct to encapsulate the http client and default headers
 type RushGo struct {
 	client         *http.Client
+	headersMu      sync.RWMutex // guards defaultHeaders, methodHeaders and the User-Agent fields
 	defaultHeaders map[string]string
+	methodHeaders  map[string]map[string]string // default headers keyed by HTTP method
 	userAgent      string // User-Agent header
+	reauth         func(rg *RushGo) error
+	reauthState    reauthState
+	credentials    *clientCredentials // set by WithClientCredentials
+	validators     []func(*http.Response) error
+	dialer         *net.Dialer
+	readDeadline   time.Duration // WithConnDeadlines, 0 for none
+	writeDeadline  time.Duration
+	dnsCache       *dnsCache
+	inFlight       inFlight
+	hostTimeouts   map[string]time.Duration
+	chunkSize      int // used by GetChunked
+	cassette       *cassette
+	baseURLs       *baseURLPool
+	requestSchema  func([]byte) error // validates PostJSON/PutJSON bodies
+	preSend        []func(*http.Request) error
+	traceHeaders   func(*http.Request)
+	jsonUseNumber  bool
+	rateLimiter    *rateLimiter
+	bodyTransforms []func([]byte) ([]byte, error)
+	proxyURL       *url.URL
+	proxyErr       error // proxy misconfiguration, fails every request
+	proxyPool      *proxyPool
+	redirectPolicy *redirectPolicy // set once a redirect option is used
+	maxBodySize    int64           // cap for helpers that read a whole body, 0 for none
+	metrics        MetricsCollector
+	requestLog     func(req *http.Request, status int, duration time.Duration, err error)
+	middleware     middlewareChain
+	rawResponses   bool // WithoutDecompression
+	bandwidth      *bandwidthLimiter
+	root           rootContext // see CancelAll
+	coalescer      *coalescer
+	verifyDigest   bool
+	priority       *requestPriority
+	securityCheck  func(SecurityReport)
+	uploadProtocol UploadProtocol
+	maxLineLength  int
+	statusMapper   func(status int, body []byte) error
+	statusMatch    func(status int) bool
+	accounting     func(bytesSent, bytesReceived int64, host string)
+	jsonMarshal    func(interface{}) ([]byte, error)
+	jsonUnmarshal  func([]byte, interface{}) error
+
+	userAgentPool   []string // replaces the built-in list for "random"
+	userAgentRandom bool
+
+	retryMax         int
+	retryBackoff     time.Duration
+	retryBuffering   bool
+	retryBufferLimit int64
 }
 
 // New initializes a new RushGo instance with optional configuration
@@ -58,6 +114,10 @@ func New(cfg *Config) *RushGo {
 			Transport: transport,
 		},
 		defaultHeaders: make(map[string]string), // Initialize the map here
+		methodHeaders:  make(map[string]map[string]string),
+		dialer:         &net.Dialer{},
+		hostTimeouts:   make(map[string]time.Duration),
+		bandwidth:      &bandwidthLimiter{},
 	}
 }
 
@@ -66,20 +126,58 @@ func (rg *RushGo) WithTimeout(timeout time.Duration) *RushGo {
     return rg
 }
 
+// WithHostTimeout overrides the client-wide timeout for requests to host, which
+// may be a hostname or host:port. Hosts without an override use the client timeout.
+func (rg *RushGo) WithHostTimeout(host string, timeout time.Duration) *RushGo {
+    rg.hostTimeouts[strings.ToLower(host)] = timeout
+    return rg
+}
+
 // WithHeaders sets default headers for the RushGo client
 func (rg *RushGo) WithHeaders(headers map[string]string) *RushGo {
+    rg.headersMu.Lock()
+    defer rg.headersMu.Unlock()
     for key, value := range headers {
         rg.defaultHeaders[key] = value
     }
     return rg
 }
 
-// WithCookies sets cookies for the RushGo client's default headers.
+// WithMethodHeaders sets default headers that are only sent with requests of the
+// given method, e.g. a JSON Content-Type for POST and PUT but not for GET
+func (rg *RushGo) WithMethodHeaders(method string, headers map[string]string) *RushGo {
+    method = strings.ToUpper(method)
+    rg.headersMu.Lock()
+    defer rg.headersMu.Unlock()
+    if rg.methodHeaders[method] == nil {
+        rg.methodHeaders[method] = make(map[string]string)
+    }
+    for key, value := range headers {
+        rg.methodHeaders[method][key] = value
+    }
+    return rg
+}
+
+// WithCookieJar stores cookies from Set-Cookie response headers and sends them
+// back on later requests to matching domains, so login sessions carry over.
+// Cookies set with WithCookies or SetCookies are still sent alongside the
+// jar's.
+func (rg *RushGo) WithCookieJar() *RushGo {
+    // cookiejar.New only fails on bad options
+    jar, _ := cookiejar.New(nil)
+    rg.client.Jar = jar
+    return rg
+}
+
+// WithCookies sets cookies for the RushGo client's default headers. They are
+// sorted by name, so the same map always gives the same Cookie header.
 func (rg *RushGo) WithCookies(cookies map[string]string) *RushGo {
     cookieStrings := []string{}
-    for name, value := range cookies {
-        cookieStrings = append(cookieStrings, fmt.Sprintf("%s=%s", name, value))
+    for _, name := range sortedKeys(cookies) {
+        cookieStrings = append(cookieStrings, fmt.Sprintf("%s=%s", name, cookies[name]))
     }
+    rg.headersMu.Lock()
+    defer rg.headersMu.Unlock()
     rg.defaultHeaders["Cookie"] = strings.Join(cookieStrings, "; ")
     return rg
 }
@@ -90,6 +188,39 @@ func (rg *RushGo) Get(url string) (*http.Response, error) {
     return rg.sendRequest("GET", url, nil)
 }
 
+// GetPath makes a GET request to a URL built from a template such as
+// "https://api.example.com/users/{id}/posts/{postId}", replacing each
+// {name} with the URL-escaped value from params. It returns an error if a
+// placeholder has no param or a param is not used.
+func (rg *RushGo) GetPath(template string, params map[string]string) (*http.Response, error) {
+    url, err := expandPath(template, params)
+    if err != nil {
+        return nil, err
+    }
+    return rg.Get(url)
+}
+
+// GetWithParams makes a GET request to baseURL with params merged into its
+// query string, escaping every key and value. Params already in baseURL are
+// kept unless params sets the same key, which replaces them.
+func (rg *RushGo) GetWithParams(baseURL string, params map[string]string) (*http.Response, error) {
+    values := make(map[string][]string, len(params))
+    for key, value := range params {
+        values[key] = []string{value}
+    }
+    return rg.GetWithParamValues(baseURL, values)
+}
+
+// GetWithParamValues is GetWithParams for repeated keys: each key is sent once
+// per value, e.g. {"tag": {"a", "b"}} becomes tag=a&tag=b
+func (rg *RushGo) GetWithParamValues(baseURL string, params map[string][]string) (*http.Response, error) {
+    url, err := mergeQuery(baseURL, params)
+    if err != nil {
+        return nil, err
+    }
+    return rg.Get(url)
+}
+
 // Post makes a POST request using the RushGo client
 func (rg *RushGo) Post(url string, body []byte) (*http.Response, error) {
     return rg.sendRequest("POST", url, body)
@@ -106,11 +237,93 @@ func (rg *RushGo) Patch(url string, body []byte) (*http.Response, error) {
     return rg.sendRequest("PATCH", url, body)
 }
 
+// PostString makes a POST request with a string body and the given Content-Type,
+// which defaults to text/plain; charset=utf-8 when empty
+func (rg *RushGo) PostString(url, body, contentType string) (*http.Response, error) {
+    return rg.sendString("POST", url, body, contentType)
+}
+
+// PutString makes a PUT request with a string body, like PostString
+func (rg *RushGo) PutString(url, body, contentType string) (*http.Response, error) {
+    return rg.sendString("PUT", url, body, contentType)
+}
+
+// PatchString makes a PATCH request with a string body, like PostString
+func (rg *RushGo) PatchString(url, body, contentType string) (*http.Response, error) {
+    return rg.sendString("PATCH", url, body, contentType)
+}
+
+func (rg *RushGo) sendString(method, url, body, contentType string) (*http.Response, error) {
+    if contentType == "" {
+        contentType = "text/plain; charset=utf-8"
+    }
+    return rg.do(method, url, []byte(body), &requestOptions{headers: map[string]string{"Content-Type": contentType}})
+}
+
+// PostForm makes a POST request with data encoded as an
+// application/x-www-form-urlencoded body. The Content-Type applies to this
+// request only; an empty map sends an empty form.
+func (rg *RushGo) PostForm(url string, data map[string]string) (*http.Response, error) {
+    return rg.sendString("POST", url, encodeForm(data), "application/x-www-form-urlencoded")
+}
+
 // Delete makes a DELETE request using the RushGo client
 func (rg *RushGo) Delete(url string) (*http.Response, error) {
     return rg.sendRequest("DELETE", url, nil)
 }
 
+// GetWithHeaders makes a GET request with extra headers for this call only.
+// They are applied on top of the default headers and win on conflict; the
+// defaults themselves are left untouched, so the client stays safe to share.
+func (rg *RushGo) GetWithHeaders(url string, headers map[string]string) (*http.Response, error) {
+    return rg.do("GET", url, nil, &requestOptions{headers: headers})
+}
+
+// PostWithHeaders makes a POST request with per-call headers, like GetWithHeaders
+func (rg *RushGo) PostWithHeaders(url string, body []byte, headers map[string]string) (*http.Response, error) {
+    return rg.do("POST", url, body, &requestOptions{headers: headers})
+}
+
+// PutWithHeaders makes a PUT request with per-call headers, like GetWithHeaders
+func (rg *RushGo) PutWithHeaders(url string, body []byte, headers map[string]string) (*http.Response, error) {
+    return rg.do("PUT", url, body, &requestOptions{headers: headers})
+}
+
+// PatchWithHeaders makes a PATCH request with per-call headers, like GetWithHeaders
+func (rg *RushGo) PatchWithHeaders(url string, body []byte, headers map[string]string) (*http.Response, error) {
+    return rg.do("PATCH", url, body, &requestOptions{headers: headers})
+}
+
+// DeleteWithHeaders makes a DELETE request with per-call headers, like GetWithHeaders
+func (rg *RushGo) DeleteWithHeaders(url string, headers map[string]string) (*http.Response, error) {
+    return rg.do("DELETE", url, nil, &requestOptions{headers: headers})
+}
+
+// GetRange makes a GET request for the bytes between start and end (inclusive).
+// A negative end requests everything from start onwards. It returns an error if
+// the server does not answer with 206 Partial Content.
+func (rg *RushGo) GetRange(url string, start, end int64) (*http.Response, error) {
+    rangeHeader := fmt.Sprintf("bytes=%d-", start)
+    if end >= 0 {
+        rangeHeader += fmt.Sprintf("%d", end)
+    }
+
+    resp, err := rg.do("GET", url, nil, &requestOptions{headers: map[string]string{"Range": rangeHeader}})
+    if err != nil {
+        return nil, err
+    }
+
+    if resp.StatusCode != http.StatusPartialContent {
+        resp.Body.Close()
+        if resp.StatusCode == http.StatusOK {
+            return nil, fmt.Errorf("server ignored range %s and sent the full body", rangeHeader)
+        }
+        return nil, fmt.Errorf("range request failed: status code %d", resp.StatusCode)
+    }
+
+    return resp, nil
+}
+
 func (rg *RushGo) Head(url string) (*http.Response, error) {
     return rg.sendRequest("HEAD", url, nil)
 }
@@ -119,63 +332,563 @@ func (rg *RushGo) Options(url string) (*http.Response, error) {
     return rg.sendRequest("OPTIONS", url, nil)
 }
 
+// WithBasicAuth sends HTTP Basic credentials in the default Authorization
+// header. Basic and bearer auth share that header, so the last of
+// WithBasicAuth, WithBearerToken or an Authorization set with WithHeaders wins
+// and replaces the others. Use ClearAuth to stop sending it.
 func (rg *RushGo) WithBasicAuth(username, password string) *RushGo {
+    rg.headersMu.Lock()
+    defer rg.headersMu.Unlock()
+    deleteHeader(rg.defaultHeaders, "Authorization")
     rg.defaultHeaders["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
     return rg
 }
 
+// WithBearerToken sends token in the default Authorization header. Like
+// WithBasicAuth, the last call setting Authorization wins.
 func (rg *RushGo) WithBearerToken(token string) *RushGo {
+    rg.headersMu.Lock()
+    defer rg.headersMu.Unlock()
+    deleteHeader(rg.defaultHeaders, "Authorization")
     rg.defaultHeaders["Authorization"] = "Bearer " + token
     return rg
 }
 
-func (rg *RushGo) FollowRedirects() *RushGo {
-    rg.client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
-        return nil
+// ClearAuth removes the default Authorization header set by WithBasicAuth,
+// WithBearerToken, WithHeaders or WithMethodHeaders, so requests go out
+// unauthenticated until auth is set again. Authorization set per request is
+// not affected.
+func (rg *RushGo) ClearAuth() *RushGo {
+    rg.headersMu.Lock()
+    defer rg.headersMu.Unlock()
+    deleteHeader(rg.defaultHeaders, "Authorization")
+    for _, headers := range rg.methodHeaders {
+        deleteHeader(headers, "Authorization")
     }
     return rg
 }
 
+// FollowRedirects follows any number of redirects. Use WithMaxRedirects to
+// set a limit instead.
+func (rg *RushGo) FollowRedirects() *RushGo {
+    return rg.WithMaxRedirects(-1)
+}
+
+// WithProxy sends requests through the given proxy. The current transport is
+// cloned with the proxy set, so HTTP/2 and other transport settings are kept.
+// If the proxy URL is invalid, or the transport can't use a proxy (HTTP/3),
+// every request fails with the error rather than silently going out direct.
+// Use SetProxy to get the error right away.
 func (rg *RushGo) WithProxy(proxyURL string) *RushGo {
-    if url, err := url.Parse(proxyURL); err == nil {
-        rg.client.Transport = &http.Transport{
-            Proxy: http.ProxyURL(url),
-        }
-    }
+    rg.proxyErr = rg.SetProxy(proxyURL)
+    return rg
+}
+
+// WithReauth sets a callback that is invoked when a request comes back with
+// 401 Unauthorized, so an expired session can be refreshed (e.g. by logging in
+// again). The original request is then retried once with the refreshed headers.
+// Requests made from inside the callback never trigger it again. When several
+// requests get a 401 at once the callback runs once, and the others wait for
+// it and then retry.
+func (rg *RushGo) WithReauth(reauth func(rg *RushGo) error) *RushGo {
+    rg.reauth = reauth
+    return rg
+}
+
+// WithResponseValidator adds a check that runs on every response. If it returns
+// an error the response body is closed and the request fails with that error.
+// Validators run in the order they were added; one that reads the body must
+// replace it for the caller.
+func (rg *RushGo) WithResponseValidator(validate func(*http.Response) error) *RushGo {
+    rg.validators = append(rg.validators, validate)
+    return rg
+}
+
+// requestOptions holds settings that apply to a single request only
+type requestOptions struct {
+    headers map[string]string // take precedence over the default headers
+    raw     bool              // return the body without decompressing it
+
+    // getBody, when set, streams the body instead of the []byte passed to do.
+    // It is called once per attempt so the body can be sent again.
+    getBody       func() (io.ReadCloser, error)
+    contentLength int64
+
+    // bodyReader is a one-shot stream body, see WithRetryBodyBuffering
+    bodyReader io.Reader
+
+    // sent, when set, receives the number of body bytes written on the last attempt
+    sent *int64
+
+    meta *RequestMeta // shared by every attempt, see Meta
+
+    priority *requestPriority // overrides the client default
+
+    // ctx is the caller's context; parent is what do derives every attempt
+    // from: ctx tied to the client's root so CancelAll still applies
+    ctx, parent context.Context
+
+    noRedirects bool // return 3xx responses instead of following them
+
+    tags map[string]string // labels for metrics, see RequestBuilder.Tag
+
+    reauthed bool // a 401 already triggered a reauth for this request
+}
+
+// noRedirectKey marks a request whose redirects must not be followed
+type noRedirectKey struct{}
+
+// WithPreSend adds a hook that runs on every outgoing request once it is fully
+// built (default headers, User-Agent and per-request options applied) and
+// right before it is sent, so it sees the final request and can still change it.
+// Returning an error cancels the request. Hooks run in the order they were added,
+// again for every attempt, and before anything at the transport level.
+func (rg *RushGo) WithPreSend(hook func(*http.Request) error) *RushGo {
+    rg.preSend = append(rg.preSend, hook)
+    return rg
+}
+
+// WithTraceHeaders sets a function that adds trace context headers, such as
+// W3C traceparent and tracestate, to every outgoing request from the span in
+// its context, linking client requests to distributed traces. Pass the
+// caller's context with GetCtx or RequestBuilder.Context so the active span
+// is there. With OpenTelemetry it is
+//
+//	rg.WithTraceHeaders(func(req *http.Request) {
+//	    otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+//	})
+//
+// inject runs for every attempt after the headers are set and before any
+// WithPreSend hook. Passing nil removes it.
+func (rg *RushGo) WithTraceHeaders(inject func(*http.Request)) *RushGo {
+    rg.traceHeaders = inject
+    return rg
+}
+
+// WithRequestBodyTransform adds a transform, e.g. encryption or signing, that
+// runs on every outgoing byte-slice body before it is sent. Transforms are
+// chained in the order they were added. Streamed bodies such as PostFile are
+// sent untouched.
+func (rg *RushGo) WithRequestBodyTransform(transform func([]byte) ([]byte, error)) *RushGo {
+    rg.bodyTransforms = append(rg.bodyTransforms, transform)
     return rg
 }
 
 // sendRequest is a helper method to make HTTP requests
 func (rg *RushGo) sendRequest(method, url string, body []byte) (*http.Response, error) {
-    req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+    return rg.do(method, url, body, nil)
+}
+
+// do makes an HTTP request with the given per-request options layered on top
+// of the client defaults
+func (rg *RushGo) do(method, url string, body []byte, opts *requestOptions) (*http.Response, error) {
+    if opts == nil {
+        opts = &requestOptions{}
+    }
+    start := time.Now()
+    opts.meta = &RequestMeta{Tags: opts.tags}
+
+    opts.parent = rg.root.get()
+    stop := context.CancelFunc(func() {})
+    if opts.ctx != nil {
+        opts.parent, stop = mergeContext(opts.ctx, opts.parent)
+    }
+
+    resp, err := rg.doWithRetry(method, url, body, opts)
+    opts.meta.Duration = time.Since(start)
+    rg.observeRequest(method, url, resp, err, opts.meta)
+    if err == nil {
+        resp, err = rg.mapStatus(resp)
+    }
+    if err != nil {
+        stop()
+        return nil, err
+    }
+    if opts.ctx != nil {
+        resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: stop}
+    }
+
+    opts.meta.Protocol = resp.Proto
+    rg.checkSecurityHeaders(resp)
+    if rg.verifyDigest {
+        resp.Body = wrapDigest(resp)
+    }
+    resp.Body = rg.throttle(resp.Request.Context(), resp.Body)
+    return resp, nil
+}
+
+// doWithRetry sends the request, retrying as configured with WithRetry
+func (rg *RushGo) doWithRetry(method, url string, body []byte, opts *requestOptions) (*http.Response, error) {
+    if err := rg.prepareOneShotBody(opts); err != nil {
+        return nil, err
+    }
+    if body != nil && opts.getBody == nil {
+        for _, transform := range rg.bodyTransforms {
+            var err error
+            if body, err = transform(body); err != nil {
+                return nil, fmt.Errorf("request body transform failed: %w", err)
+            }
+        }
+    }
+
+    for attempt := 0; ; attempt++ {
+        if attempt > 0 {
+            if err := sleepContext(opts.parent, rg.retryDelay(attempt)); err != nil {
+                return nil, err
+            }
+        }
+
+        resp, err := rg.attempt(method, url, body, opts)
+        if err == nil && !opts.raw && !rg.rawResponses {
+            decompressBody(resp)
+        }
+        retry := isRetryable(resp, err)
+        if err == nil {
+            if err = rg.validate(resp); err != nil {
+                resp.Body.Close()
+                resp = nil
+                retry = true
+            }
+        }
+
+        if !retry || attempt >= rg.retryMax {
+            if err != nil {
+                return nil, err
+            }
+            return resp, nil
+        }
+        if resp != nil {
+            resp.Body.Close()
+        }
+    }
+}
+
+// attempt sends the request once, failing over between base URLs for
+// relative paths
+func (rg *RushGo) attempt(method, url string, body []byte, opts *requestOptions) (*http.Response, error) {
+    if rg.baseURLs != nil && isRelativeURL(url) {
+        return rg.doWithFailover(method, url, body, opts)
+    }
+    return rg.doWithReauth(method, url, body, opts)
+}
+
+// validate runs the response validators in order, stopping at the first error
+func (rg *RushGo) validate(resp *http.Response) error {
+    for _, validate := range rg.validators {
+        if err := validate(resp); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// doWithReauth sends the request and, on a 401 with a reauth callback or
+// client credentials set, refreshes the session and sends it once more. A 401
+// that arrives while another request is already refreshing waits for that
+// refresh and reuses it.
+func (rg *RushGo) doWithReauth(method, url string, body []byte, opts *requestOptions) (*http.Response, error) {
+    req, err := rg.newRequest(method, url, body, opts)
+    if err != nil {
+        return nil, err
+    }
+
+    gen, refreshing := rg.reauthState.snapshot()
+    resp, err := rg.roundTrip(req)
+    if err != nil || resp.StatusCode != http.StatusUnauthorized || (rg.reauth == nil && rg.credentials == nil) || opts.reauthed {
+        return resp, err
+    }
+
+    // A request sent while the callback was running may be the callback's own
+    // login call, so it gets its 401 back instead of waiting on itself
+    if refreshing && !rg.reauthState.completedSince(gen) {
+        return resp, nil
+    }
+    opts.reauthed = true
+    resp.Body.Close()
+    if err := rg.reauthState.refresh(gen, rg.reauthenticate); err != nil {
+        return nil, fmt.Errorf("reauth failed: %w", err)
+    }
+
+    // Rebuild the request so the refreshed headers and cookies are picked up
+    req, err = rg.newRequest(method, url, body, opts)
+    if err != nil {
+        return nil, err
+    }
+    return rg.roundTrip(req)
+}
+
+// reauthenticate drops the client credentials token, so the retry fetches a
+// new one, and runs the WithReauth callback
+func (rg *RushGo) reauthenticate() error {
+    if rg.credentials != nil {
+        rg.credentials.invalidate()
+    }
+    if rg.reauth != nil {
+        return rg.reauth(rg)
+    }
+    return nil
+}
+
+// roundTrip sends req with the underlying http.Client while counting it as in
+// flight, from before it is sent until its body is read to the end or closed
+func (rg *RushGo) roundTrip(req *http.Request) (*http.Response, error) {
+    if err := rg.inFlight.add(); err != nil {
+        return nil, err
+    }
+    resp, err := rg.roundTripCounted(req)
+    if err != nil {
+        rg.inFlight.done()
+        return nil, err
+    }
+    resp.Body = &inFlightBody{ReadCloser: resp.Body, done: rg.inFlight.done}
+    return resp, nil
+}
+
+// roundTripCounted is roundTrip past the in-flight count
+func (rg *RushGo) roundTripCounted(req *http.Request) (*http.Response, error) {
+    if meta := metaFromContext(req.Context()); meta != nil {
+        meta.Attempts++
+        meta.Cached = false
+    }
+
+    send := rg.send
+    if rg.accounting != nil {
+        send = rg.account(send)
+    }
+    if rg.rateLimiter != nil && rg.rateLimiter.adaptive {
+        send = rg.rateLimiter.observe(send)
+    }
+    if rg.proxyPool != nil {
+        send = rg.proxyPool.track(send)
+    }
+    if rg.cassette != nil {
+        live := send
+        send = func(req *http.Request) (*http.Response, error) {
+            return rg.cassette.roundTrip(req, live)
+        }
+    }
+    if rg.coalescer != nil {
+        return rg.coalescer.do(req, send, rg.coalesceLimit())
+    }
+    return send(req)
+}
+
+// send hands req to the http.Client, applying the rate limit and any per-host timeout
+func (rg *RushGo) send(req *http.Request) (resp *http.Response, err error) {
+    if rg.rateLimiter != nil {
+        if err := rg.rateLimiter.wait(req.Context()); err != nil {
+            if req.Body != nil {
+                req.Body.Close()
+            }
+            return nil, err
+        }
+    }
+
+    if rg.requestLog != nil {
+        start := time.Now()
+        defer func() { rg.logRequest(req, resp, err, time.Since(start)) }()
+    }
+
+    client := rg.client
+    if req.Context().Value(noRedirectKey{}) != nil {
+        noFollow := *client
+        noFollow.CheckRedirect = func(*http.Request, []*http.Request) error {
+            return http.ErrUseLastResponse
+        }
+        client = &noFollow
+    }
+    if transport, ok := rg.middleware.wrap(client.Transport); ok {
+        wrapped := *client
+        wrapped.Transport = transport
+        client = &wrapped
+    }
+
+    timeout, ok := rg.hostTimeout(req.URL)
+    if !ok {
+        resp, err = client.Do(req)
+        setResponseRequest(resp, req)
+        return resp, err
+    }
+
+    // The per-host deadline replaces the client-wide one, so it can be longer too
+    ctx, cancel := context.WithTimeout(req.Context(), timeout)
+    hostClient := *client
+    hostClient.Timeout = 0
+    resp, err = hostClient.Do(req.WithContext(ctx))
+    if err != nil {
+        cancel()
+        return nil, err
+    }
+    setResponseRequest(resp, req)
+    resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+    return resp, nil
+}
+
+// setResponseRequest fills in resp.Request when the transport left it nil,
+// as a middleware or custom transport returning a response it built itself
+// may. http.Client doesn't set it, and the helpers rely on it for the
+// request's context.
+func setResponseRequest(resp *http.Response, req *http.Request) {
+    if resp != nil && resp.Request == nil {
+        resp.Request = req
+    }
+}
+
+// hostTimeout looks up a WithHostTimeout override for u, trying host:port first
+func (rg *RushGo) hostTimeout(u *url.URL) (time.Duration, bool) {
+    if timeout, ok := rg.hostTimeouts[strings.ToLower(u.Host)]; ok {
+        return timeout, true
+    }
+    timeout, ok := rg.hostTimeouts[strings.ToLower(u.Hostname())]
+    return timeout, ok
+}
+
+// clientKey is the request context key holding the RushGo that sent the request
+type clientKey struct{}
+
+// clientFromResponse returns the RushGo that made resp, or nil if it was not
+// made through RushGo
+func clientFromResponse(resp *http.Response) *RushGo {
+    if resp == nil || resp.Request == nil {
+        return nil
+    }
+    rg, _ := resp.Request.Context().Value(clientKey{}).(*RushGo)
+    return rg
+}
+
+// cancelOnClose releases a request context once the response body is closed
+type cancelOnClose struct {
+    io.ReadCloser
+    cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+    err := c.ReadCloser.Close()
+    c.cancel()
+    return err
+}
+
+// newRequest builds an *http.Request with the default headers, User-Agent and
+// per-request options applied
+func (rg *RushGo) newRequest(method, url string, body []byte, opts *requestOptions) (*http.Request, error) {
+    if rg.proxyErr != nil {
+        return nil, rg.proxyErr
+    }
+
+    var req *http.Request
+    var err error
+    if opts.getBody != nil {
+        req, err = newStreamingRequest(method, url, opts.getBody, opts.contentLength)
+    } else {
+        req, err = http.NewRequest(method, url, bytes.NewBuffer(body))
+    }
     if err != nil {
         return nil, err
     }
 
-    // Apply default headers to the request
+    // Let package-level helpers that only see the response find the client settings
+    parent := opts.parent
+    if parent == nil {
+        parent = rg.root.get()
+    }
+    ctx := context.WithValue(parent, clientKey{}, rg)
+    if opts.meta != nil {
+        ctx = context.WithValue(ctx, metaKey{}, opts.meta)
+    }
+    if opts.noRedirects {
+        ctx = context.WithValue(ctx, noRedirectKey{}, true)
+    }
+    req = req.WithContext(ctx)
+
+    if req.Body != nil && req.Body != http.NoBody {
+        req.Body = rg.throttle(req.Context(), req.Body)
+    }
+
+    if opts.sent != nil && req.Body != nil && req.Body != http.NoBody {
+        atomic.StoreInt64(opts.sent, 0)
+        req.Body = &countingReadCloser{countingReader{req.Body, opts.sent}, req.Body}
+    }
+
+    // Apply default headers to the request, copied under the read lock so
+    // concurrent SetHeaders calls can't race with it
+    rg.headersMu.RLock()
     for key, value := range rg.defaultHeaders {
         req.Header.Set(key, value)
     }
+    for key, value := range rg.methodHeaders[req.Method] {
+        req.Header.Set(key, value)
+    }
 
     // Set User-Agent header if it's provided
     if rg.userAgent != "" {
         req.Header.Set("User-Agent", rg.userAgent)
     }
+    rg.headersMu.RUnlock()
+
+    for key, value := range opts.headers {
+        req.Header.Set(key, value)
+    }
+
+    priority := rg.priority
+    if opts.priority != nil {
+        priority = opts.priority
+    }
+    rg.applyPriority(req, priority)
+
+    // The transport only decompresses transparently when it adds
+    // Accept-Encoding itself, so asking for gzip explicitly keeps the body raw
+    if (opts.raw || rg.rawResponses) && req.Header.Get("Accept-Encoding") == "" {
+        req.Header.Set("Accept-Encoding", "gzip")
+    }
+
+    if rg.traceHeaders != nil {
+        rg.traceHeaders(req)
+    }
 
-    return rg.client.Do(req)
+    for _, hook := range rg.preSend {
+        if err := hook(req); err != nil {
+            if req.Body != nil {
+                req.Body.Close()
+            }
+            return nil, err
+        }
+    }
+
+    return req, nil
+}
+
+// defaultHeader returns a single default header
+func (rg *RushGo) defaultHeader(key string) string {
+    rg.headersMu.RLock()
+    defer rg.headersMu.RUnlock()
+    return rg.defaultHeaders[key]
+}
+
+// currentUserAgent returns the User-Agent set on the client
+func (rg *RushGo) currentUserAgent() string {
+    rg.headersMu.RLock()
+    defer rg.headersMu.RUnlock()
+    return rg.userAgent
 }
 
 func (rg *RushGo) SetHeaders(headers map[string]string) *RushGo {
+    rg.headersMu.Lock()
+    defer rg.headersMu.Unlock()
     for key, value := range headers {
         rg.defaultHeaders[key] = value
     }
     return rg
 }
 
-// SetCookies sets cookies for the RushGo client without replacing the existing ones.
+// SetCookies sets cookies for the RushGo client without replacing the existing
+// ones. The new cookies are appended sorted by name, like WithCookies.
 func (rg *RushGo) SetCookies(cookies map[string]string) *RushGo {
+    rg.headersMu.Lock()
+    defer rg.headersMu.Unlock()
+
     // Merge the new cookies with the existing ones
-    for name, value := range cookies {
+    for _, name := range sortedKeys(cookies) {
+        value := cookies[name]
         existingValue, exists := rg.defaultHeaders["Cookie"]
         if exists {
             // Append the new cookie to the existing ones
@@ -188,10 +901,28 @@ func (rg *RushGo) SetCookies(cookies map[string]string) *RushGo {
     return rg
 }
 
+// GetDefaultCookies returns the cookies in the default Cookie header as a
+// name to value map. Values may themselves contain '='. The map is empty
+// when no cookies are set.
+func (rg *RushGo) GetDefaultCookies() map[string]string {
+    cookies := make(map[string]string)
+    for _, pair := range strings.Split(rg.defaultHeader("Cookie"), ";") {
+        name, value, found := strings.Cut(strings.TrimSpace(pair), "=")
+        if !found || strings.TrimSpace(name) == "" {
+            continue
+        }
+        cookies[strings.TrimSpace(name)] = strings.TrimSpace(value)
+    }
+    return cookies
+}
+
 func (rg *RushGo) WithUserAgent(userAgent string) *RushGo {
-    if userAgent == "random" {
+    rg.headersMu.Lock()
+    defer rg.headersMu.Unlock()
+    rg.userAgentRandom = userAgent == "random"
+    if rg.userAgentRandom {
         // Generate and set a random User-Agent
-        rg.userAgent = RandUserAgent().String()
+        rg.userAgent = rg.randomUserAgent()
     } else {
         rg.userAgent = userAgent
     }
@@ -199,34 +930,65 @@ func (rg *RushGo) WithUserAgent(userAgent string) *RushGo {
 }
 
 
+// WebSocketConnect opens a WebSocket connection, sending the default headers
+// with the handshake. Use WebSocketConnectWithOptions to set an Origin.
 func (rg *RushGo) WebSocketConnect(urlStr string) (*websocket.Conn, *http.Response, error) {
-    // You can customize the Dialer if you need to set timeouts or other settings
-    dialer := websocket.DefaultDialer
+    return rg.WebSocketConnectWithOptions(urlStr, WebSocketOptions{})
+}
 
-    // Pass headers if needed
-    headers := http.Header{}
-    for key, value := range rg.defaultHeaders {
-        headers.Add(key, value)
-    }
+// DownloadImage downloads an image from the given URL and saves it to the specified path.
+// If savePath is nil, the image is saved in the current working directory,
+// named like DownloadFile does, with .jpg when the type has no known extension.
+// Any image type is accepted unless AcceptFormat asks for a specific one.
+// With WithRetry set, a download cut off mid-body is removed and fetched again.
+// If the request's context is canceled or times out mid-body, the partial file
+// is removed and a *DownloadCanceledError is returned.
+// It returns the http.Response and an error, if any.
+func (rg *RushGo) DownloadImage(url string, savePath *string, opts ...DownloadOption) (*http.Response, error) {
+    return rg.DownloadFile(url, savePath, append([]DownloadOption{imageDownload}, opts...)...)
+}
 
-    // Connect to the WebSocket server
-    conn, resp, err := dialer.Dial(urlStr, headers)
-    if err != nil {
-        return nil, nil, err
-    }
+// DownloadImageCtx is DownloadImage bound to ctx. Canceling ctx aborts the
+// download, also while the body is being written, with a *DownloadCanceledError.
+func (rg *RushGo) DownloadImageCtx(ctx context.Context, url string, savePath *string, opts ...DownloadOption) (*http.Response, error) {
+    return rg.DownloadFileCtx(ctx, url, savePath, append([]DownloadOption{imageDownload}, opts...)...)
+}
 
-    return conn, resp, err
+// DownloadFile downloads any kind of file from url and saves it to savePath.
+// If savePath is nil, the file goes in the current working directory under
+// the filename from the Content-Disposition header or else the last segment
+// of the URL path, with the extension matching the returned Content-Type.
+// It retries and cleans up after a cut-off or canceled body like DownloadImage.
+func (rg *RushGo) DownloadFile(url string, savePath *string, opts ...DownloadOption) (*http.Response, error) {
+    return rg.downloadWithRetry(nil, url, savePath, opts)
 }
 
+// DownloadFileCtx is DownloadFile bound to ctx
+func (rg *RushGo) DownloadFileCtx(ctx context.Context, url string, savePath *string, opts ...DownloadOption) (*http.Response, error) {
+    return rg.downloadWithRetry(ctx, url, savePath, opts)
+}
 
+// downloadWithRetry runs downloadFile under retryOnTruncation. A nil ctx
+// means no caller context.
+func (rg *RushGo) downloadWithRetry(ctx context.Context, url string, savePath *string, opts []DownloadOption) (*http.Response, error) {
+    var resp *http.Response
+    err := rg.retryOnTruncation(ctx, func() (err error) {
+        resp, err = rg.downloadFile(ctx, url, savePath, opts)
+        return err
+    })
+    return resp, err
+}
 
+func (rg *RushGo) downloadFile(ctx context.Context, url string, savePath *string, opts []DownloadOption) (*http.Response, error) {
+    start := time.Now()
+    cfg := newDownloadConfig(opts)
+    headers := map[string]string{}
+    if accept := cfg.acceptHeader(); accept != "" {
+        headers["Accept"] = accept
+    }
 
-// DownloadImage downloads an image from the given URL and saves it to the specified path.
-// If savePath is nil, the image is saved in the current working directory with its original filename.
-// It returns the http.Response and an error, if any.
-func (rg *RushGo) DownloadImage(url string, savePath *string) (*http.Response, error) {
-    // Make a GET request to the image URL
-    resp, err := rg.Get(url)
+    // Make a GET request to the file URL
+    resp, err := rg.do("GET", url, nil, &requestOptions{headers: headers, ctx: ctx})
     if err != nil {
         return nil, err
     }
@@ -234,38 +996,47 @@ func (rg *RushGo) DownloadImage(url string, savePath *string) (*http.Response, e
 
     // Check if the response status code is 200 (OK)
     if resp.StatusCode != http.StatusOK {
-        return nil, fmt.Errorf("failed to download image: status code %d", resp.StatusCode)
+        return nil, fmt.Errorf("failed to download %s: status code %d", cfg.kind(), resp.StatusCode)
     }
 
     // Determine the save path
     var finalPath string
     if savePath == nil {
-        // Extract filename from the URL
-        _, fileName := path.Split(url)
-        // Determine the file extension from the Content-Type header
-        contentType := resp.Header.Get("Content-Type")
-        ext := ".jpg" // Default extension if Content-Type is not available or not recognized
-        if contentType != "" {
-            ext = "." + strings.Split(contentType, "/")[1]
-        }
-        finalPath = filepath.Join(".", fileName+ext)
+        finalPath = filepath.Join(".", downloadFileName(url, resp.Header, cfg.fallbackExt()))
     } else {
         // Use the provided path
         finalPath = *savePath
     }
 
-    // Create a file to save the image
+    // Create a file to save the download
     file, err := os.Create(finalPath)
     if err != nil {
         return nil, err
     }
     defer file.Close()
 
-    // Copy the image data from the response to the file
-    _, err = io.Copy(file, resp.Body)
+    // Copy the data from the response to the file
+    dst, sum := cfg.bodyWriter(file)
+    copyStart := time.Now()
+    written, err := io.Copy(dst, cfg.bodyReader(resp))
     if err != nil {
+        canceled := isCanceled(err)
+        if canceled || errors.Is(err, ErrChecksumMismatch) || isTruncatedBody(err) {
+            file.Close()
+            os.Remove(finalPath)
+        }
+        if canceled {
+            return nil, &DownloadCanceledError{Written: written, Err: err}
+        }
+        return nil, err
+    }
+
+    if err := cfg.verify(resp, sum); err != nil {
+        file.Close()
+        os.Remove(finalPath)
         return nil, err
     }
+    cfg.record(written, start, copyStart)
 
     return resp, nil
 }
@@ -0,0 +1,89 @@
+package rushgo
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// TestConcurrentHeaderUpdates changes the default headers and cookies while
+// requests are being built from them. Run it with -race.
+func TestConcurrentHeaderUpdates(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	rg := New(nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				resp, err := rg.Get(srv.URL)
+				if err != nil {
+					t.Errorf("Get: %v", err)
+					return
+				}
+				resp.Body.Close()
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				rg.SetHeaders(map[string]string{fmt.Sprintf("X-Worker-%d", i): fmt.Sprint(j)})
+				rg.SetCookies(map[string]string{fmt.Sprintf("c%d", i): fmt.Sprint(j)})
+				rg.GetDefaultCookies()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestWithCookieJarKeepsSession(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cret", Path: "/"})
+	})
+	mux.HandleFunc("/private", func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "s3cret" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	rg := New(nil).WithCookieJar()
+	resp, err := rg.Post(srv.URL+"/login", nil)
+	if err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	resp.Body.Close()
+
+	resp, err = rg.Get(srv.URL + "/private")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("protected endpoint status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestCookieHeaderIsSorted(t *testing.T) {
+	cookies := map[string]string{"zeta": "1", "alpha": "2", "mid": "3", "beta": "4"}
+	const want = "alpha=2; beta=4; mid=3; zeta=1"
+
+	// Map order changes between iterations, so build it more than once
+	for i := 0; i < 10; i++ {
+		if got := New(nil).WithCookies(cookies).defaultHeader("Cookie"); got != want {
+			t.Fatalf("WithCookies Cookie header = %q, want %q", got, want)
+		}
+	}
+
+	rg := New(nil).WithCookies(map[string]string{"session": "x"}).SetCookies(map[string]string{"b": "2", "a": "1"})
+	if got := rg.defaultHeader("Cookie"); got != "session=x; a=1; b=2" {
+		t.Errorf("SetCookies Cookie header = %q, want %q", got, "session=x; a=1; b=2")
+	}
+}
@@ -0,0 +1,50 @@
+package rushgo
+
+import (
+	"net/http"
+	"time"
+)
+
+// RequestObservation describes one finished request for a MetricsCollector
+type RequestObservation struct {
+	Method     string
+	URL        string
+	StatusCode int // 0 when the request failed without a response
+	Err        error
+	Duration   time.Duration // until the response headers arrived, including retries
+	Attempts   int
+	Tags       map[string]string // labels set with RequestBuilder.Tag, nil if none
+}
+
+// MetricsCollector receives an observation for every request the client makes
+type MetricsCollector interface {
+	ObserveRequest(obs RequestObservation)
+}
+
+// WithMetrics passes every request to collector once its response headers
+// have arrived or it has failed. Retries, reauth and failover count as one
+// request. ObserveRequest is called on the goroutine making the request, so
+// it should be quick and safe for concurrent use.
+func (rg *RushGo) WithMetrics(collector MetricsCollector) *RushGo {
+	rg.metrics = collector
+	return rg
+}
+
+// observeRequest reports a finished request to the metrics collector
+func (rg *RushGo) observeRequest(method, url string, resp *http.Response, err error, meta *RequestMeta) {
+	if rg.metrics == nil {
+		return
+	}
+	obs := RequestObservation{
+		Method:   method,
+		URL:      url,
+		Err:      err,
+		Duration: meta.Duration,
+		Attempts: meta.Attempts,
+		Tags:     meta.Tags,
+	}
+	if resp != nil {
+		obs.StatusCode = resp.StatusCode
+	}
+	rg.metrics.ObserveRequest(obs)
+}
@@ -0,0 +1,62 @@
+package rushgo
+
+import (
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// Use wraps the client's transport in middleware, for cross-cutting behavior
+// such as tracing headers, token refresh or metrics. The first middleware
+// registered runs outermost: with Use(a, b), a sees the request first and the
+// response last. Middleware sits below the rest of RushGo, so it runs once
+// per attempt and once per redirect hop, and sees requests with the default
+// headers, cookies and auth already applied. It keeps working when an option
+// such as WithProxy or WithTLSConfig later swaps the underlying transport, as
+// the chain is rebuilt around the new one.
+func (rg *RushGo) Use(middleware ...func(http.RoundTripper) http.RoundTripper) *RushGo {
+	rg.middleware.add(middleware)
+	return rg
+}
+
+// middlewareChain holds the Use middleware and the transport last built from
+// it, so the chain is only rebuilt when the base transport changes
+type middlewareChain struct {
+	mu      sync.Mutex
+	funcs   []func(http.RoundTripper) http.RoundTripper
+	base    http.RoundTripper
+	wrapped http.RoundTripper
+}
+
+func (c *middlewareChain) add(funcs []func(http.RoundTripper) http.RoundTripper) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, fn := range funcs {
+		if fn != nil {
+			c.funcs = append(c.funcs, fn)
+		}
+	}
+	c.base, c.wrapped = nil, nil
+}
+
+// wrap returns base wrapped in the middleware, or false if there is none
+func (c *middlewareChain) wrap(base http.RoundTripper) (http.RoundTripper, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.funcs) == 0 {
+		return nil, false
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	// A transport of an uncomparable type, such as a func, can't be told
+	// apart from the last one, so its chain is built every time
+	if c.wrapped == nil || !reflect.TypeOf(base).Comparable() || c.base != base {
+		wrapped := base
+		for i := len(c.funcs) - 1; i >= 0; i-- {
+			wrapped = c.funcs[i](wrapped)
+		}
+		c.base, c.wrapped = base, wrapped
+	}
+	return c.wrapped, true
+}
@@ -0,0 +1,80 @@
+package rushgo
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+// shortCircuit answers every request itself without calling the transport,
+// leaving resp.Request nil the way a cache or mock might
+func shortCircuit(body string) func(http.RoundTripper) http.RoundTripper {
+	return func(http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(*http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Status:     "200 OK",
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}, nil
+		})
+	}
+}
+
+func TestUseShortCircuitResponse(t *testing.T) {
+	rg := New(nil).Use(shortCircuit(`[1,2,3]`))
+
+	resp, err := rg.Get("http://example.invalid/items")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if resp.Request == nil {
+		t.Fatal("resp.Request is nil")
+	}
+	body, err := BodyString(resp)
+	if err != nil {
+		t.Fatalf("BodyString: %v", err)
+	}
+	if body != `[1,2,3]` {
+		t.Errorf("body = %q, want %q", body, `[1,2,3]`)
+	}
+
+	var items []int
+	err = GetJSONArray(rg, "http://example.invalid/items", func(item int) error {
+		items = append(items, item)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GetJSONArray: %v", err)
+	}
+	if len(items) != 3 {
+		t.Errorf("items = %v, want 3 items", items)
+	}
+}
+
+func TestUseOrder(t *testing.T) {
+	var order []string
+	record := func(name string) func(http.RoundTripper) http.RoundTripper {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	rg := New(nil).Use(record("outer"), record("inner"), shortCircuit(`ok`))
+
+	resp, err := rg.Get("http://example.invalid/")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if strings.Join(order, ",") != "outer,inner" {
+		t.Errorf("order = %v, want [outer inner]", order)
+	}
+}
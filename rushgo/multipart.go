@@ -0,0 +1,172 @@
+package rushgo
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MultipartFile is a file to upload as one part of a multipart body
+type MultipartFile struct {
+	Field string // form field name
+	Path  string // local file path, its base name is sent as the filename
+
+	// Compress gzips the part on the fly and marks it with a part-level
+	// Content-Encoding: gzip header. Only use it when the server is known to
+	// decode such parts; plain form parsers will see the compressed bytes.
+	Compress bool
+}
+
+// PostMultipart uploads fields and files as multipart/form-data, where files
+// maps form field names to local file paths. Files are streamed, never read
+// into memory, and a missing file is reported before anything is sent. The
+// multipart Content-Type applies to this request only.
+func (rg *RushGo) PostMultipart(url string, fields map[string]string, files map[string]string) (*http.Response, error) {
+	return rg.postMultipart(url, "", fields, sortedMultipartFiles(files))
+}
+
+// PostMultipartFiles uploads fields and files as multipart/form-data like
+// PostMultipartWithBoundary, with a random boundary and per-file settings
+func (rg *RushGo) PostMultipartFiles(url string, fields map[string]string, files []MultipartFile) (*http.Response, error) {
+	return rg.postMultipart(url, "", fields, files)
+}
+
+// PostMultipartWithBoundary uploads fields and files as multipart/form-data
+// using the given boundary, for servers that need a specific one. files maps
+// form field names to local file paths. The boundary must follow RFC 2046
+// (1 to 70 characters from digits, letters and '()+_,-./:=? and space, not
+// ending in a space) and is checked before anything is sent.
+func (rg *RushGo) PostMultipartWithBoundary(url, boundary string, fields map[string]string, files map[string]string) (*http.Response, error) {
+	if err := validateBoundary(boundary); err != nil {
+		return nil, err
+	}
+	return rg.postMultipart(url, boundary, fields, sortedMultipartFiles(files))
+}
+
+// validateBoundary checks a multipart boundary against RFC 2046
+func validateBoundary(boundary string) error {
+	if len(boundary) < 1 || len(boundary) > 70 {
+		return fmt.Errorf("invalid multipart boundary %q: must be 1 to 70 characters", boundary)
+	}
+	if strings.HasSuffix(boundary, " ") {
+		return fmt.Errorf("invalid multipart boundary %q: must not end with a space", boundary)
+	}
+	for _, c := range boundary {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		case strings.ContainsRune("'()+_,-./:=? ", c):
+		default:
+			return fmt.Errorf("invalid multipart boundary %q: character %q is not allowed", boundary, c)
+		}
+	}
+	return nil
+}
+
+func sortedMultipartFiles(files map[string]string) []MultipartFile {
+	parts := make([]MultipartFile, 0, len(files))
+	for field, path := range files {
+		parts = append(parts, MultipartFile{Field: field, Path: path})
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].Field < parts[j].Field })
+	return parts
+}
+
+// postMultipart streams a multipart/form-data body through a pipe so files are
+// never held in memory. An empty boundary gets a random one.
+func (rg *RushGo) postMultipart(url, boundary string, fields map[string]string, files []MultipartFile) (*http.Response, error) {
+	for _, file := range files {
+		if _, err := os.Stat(file.Path); err != nil {
+			return nil, fmt.Errorf("failed to open file %s: %w", file.Path, err)
+		}
+	}
+
+	fieldNames := make([]string, 0, len(fields))
+	for name := range fields {
+		fieldNames = append(fieldNames, name)
+	}
+	sort.Strings(fieldNames)
+
+	// The Content-Type comes from a throwaway writer so it matches the boundary
+	// every streamed body will use
+	probe := multipart.NewWriter(io.Discard)
+	if boundary != "" {
+		if err := probe.SetBoundary(boundary); err != nil {
+			return nil, err
+		}
+	}
+	boundary = probe.Boundary()
+
+	getBody := func() (io.ReadCloser, error) {
+		reader, writer := io.Pipe()
+		mw := multipart.NewWriter(writer)
+		if err := mw.SetBoundary(boundary); err != nil {
+			return nil, err
+		}
+
+		go func() {
+			writer.CloseWithError(writeMultipart(mw, fieldNames, fields, files))
+		}()
+		return reader, nil
+	}
+
+	return rg.do("POST", url, nil, &requestOptions{
+		headers: map[string]string{"Content-Type": probe.FormDataContentType()},
+		getBody: getBody,
+	})
+}
+
+func writeMultipart(mw *multipart.Writer, fieldNames []string, fields map[string]string, files []MultipartFile) error {
+	for _, name := range fieldNames {
+		if err := mw.WriteField(name, fields[name]); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range files {
+		if err := writeMultipartFile(mw, file); err != nil {
+			return err
+		}
+	}
+
+	return mw.Close()
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func writeMultipartFile(mw *multipart.Writer, file MultipartFile) error {
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open file %s: %w", file.Path, err)
+	}
+	defer f.Close()
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(file.Field), quoteEscaper.Replace(filepath.Base(file.Path))))
+	header.Set("Content-Type", "application/octet-stream")
+	if file.Compress {
+		header.Set("Content-Encoding", "gzip")
+	}
+
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	if !file.Compress {
+		_, err = io.Copy(part, f)
+		return err
+	}
+
+	zw := gzip.NewWriter(part)
+	if _, err := io.Copy(zw, f); err != nil {
+		return err
+	}
+	return zw.Close()
+}
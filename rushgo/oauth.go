@@ -0,0 +1,151 @@
+package rushgo
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenError is returned when the token endpoint rejects a client-credentials
+// request. Code and Description come from the RFC 6749 error response, if the
+// server sent one.
+type TokenError struct {
+	StatusCode  int
+	Code        string // e.g. "invalid_client" or "invalid_scope"
+	Description string
+}
+
+func (e *TokenError) Error() string {
+	msg := fmt.Sprintf("token endpoint returned status %d", e.StatusCode)
+	if e.Code != "" {
+		msg += ": " + e.Code
+	}
+	if e.Description != "" {
+		msg += " (" + e.Description + ")"
+	}
+	return msg
+}
+
+// WithClientCredentials authenticates every request with an OAuth2 access
+// token obtained through the client-credentials grant. The token is fetched
+// from tokenURL with this client on first use, cached, and refreshed shortly
+// before it expires. A request that comes back 401 Unauthorized, e.g. because
+// the token was revoked, drops the cached token and is sent once more with a
+// new one, alongside any WithReauth callback. A failed token request cancels
+// the request it was fetched for with an error wrapping a *TokenError.
+func (rg *RushGo) WithClientCredentials(tokenURL, clientID, clientSecret string, scopes []string) *RushGo {
+	cc := &clientCredentials{
+		rg:           rg,
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scopes:       scopes,
+	}
+	rg.credentials = cc
+	return rg.WithPreSend(cc.authorize)
+}
+
+// clientCredentials caches the token for WithClientCredentials
+type clientCredentials struct {
+	rg                     *RushGo
+	tokenURL               string
+	clientID, clientSecret string
+	scopes                 []string
+
+	mu      sync.Mutex
+	token   string
+	refresh time.Time // zero when the token does not expire
+}
+
+// authorize sets the bearer token on req, leaving the token request alone
+func (cc *clientCredentials) authorize(req *http.Request) error {
+	if target, err := url.Parse(cc.tokenURL); err == nil &&
+		req.URL.Scheme == target.Scheme && req.URL.Host == target.Host && req.URL.Path == target.Path {
+		return nil
+	}
+
+	token, err := cc.get()
+	if err != nil {
+		return fmt.Errorf("failed to fetch client credentials token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+// invalidate drops the cached token so the next request fetches a new one
+func (cc *clientCredentials) invalidate() {
+	cc.mu.Lock()
+	cc.token = ""
+	cc.mu.Unlock()
+}
+
+// get returns the cached token, fetching a new one if it is missing or about
+// to expire. The lock is held during the fetch so concurrent requests share it.
+func (cc *clientCredentials) get() (string, error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.token != "" && (cc.refresh.IsZero() || time.Now().Before(cc.refresh)) {
+		return cc.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(cc.scopes) > 0 {
+		form.Set("scope", strings.Join(cc.scopes, " "))
+	}
+	// RFC 6749 section 2.3.1 wants the credentials form-encoded before base64
+	credentials := url.QueryEscape(cc.clientID) + ":" + url.QueryEscape(cc.clientSecret)
+
+	// reauthed keeps a 401 from the token endpoint from invalidating the token
+	// while the lock is held
+	resp, err := cc.rg.do("POST", cc.tokenURL, []byte(form.Encode()), &requestOptions{reauthed: true, headers: map[string]string{
+		"Content-Type":  "application/x-www-form-urlencoded",
+		"Accept":        "application/json",
+		"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials)),
+	}})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	decodeErr := json.Unmarshal(data, &result)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", &TokenError{StatusCode: resp.StatusCode, Code: result.Error, Description: result.ErrorDescription}
+	}
+	if decodeErr != nil {
+		return "", fmt.Errorf("failed to parse token response: %w", decodeErr)
+	}
+	if result.AccessToken == "" {
+		return "", &TokenError{StatusCode: resp.StatusCode, Code: result.Error, Description: "response has no access_token"}
+	}
+
+	cc.token = result.AccessToken
+	cc.refresh = time.Time{}
+	if result.ExpiresIn > 0 {
+		// Refresh a tenth of the lifetime early, but at most a minute
+		lifetime := time.Duration(result.ExpiresIn) * time.Second
+		early := lifetime / 10
+		if early > time.Minute {
+			early = time.Minute
+		}
+		cc.refresh = time.Now().Add(lifetime - early)
+	}
+	return cc.token, nil
+}
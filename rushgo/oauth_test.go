@@ -0,0 +1,131 @@
+package rushgo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// oauthServer issues tokens t1, t2, ... from /token and accepts only the
+// latest one that hasn't been revoked on /api
+type oauthServer struct {
+	*httptest.Server
+	issued  int32
+	mu      sync.Mutex
+	valid   string
+	revoked bool
+}
+
+func newOAuthServer(t *testing.T) *oauthServer {
+	t.Helper()
+	s := &oauthServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		switch r.URL.Path {
+		case "/token":
+			s.valid = fmt.Sprintf("t%d", atomic.AddInt32(&s.issued, 1))
+			s.revoked = false
+			fmt.Fprintf(w, `{"access_token":%q,"expires_in":3600}`, s.valid)
+		case "/api":
+			if s.revoked || r.Header.Get("Authorization") != "Bearer "+s.valid {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(s.valid))
+		}
+	}))
+	return s
+}
+
+func (s *oauthServer) revoke() {
+	s.mu.Lock()
+	s.revoked = true
+	s.mu.Unlock()
+}
+
+func TestClientCredentialsRefetchesRevokedToken(t *testing.T) {
+	srv := newOAuthServer(t)
+	defer srv.Close()
+	rg := New(nil).WithClientCredentials(srv.URL+"/token", "id", "secret", nil)
+
+	get := func() {
+		t.Helper()
+		resp, err := rg.Get(srv.URL + "/api")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d, want 200", resp.StatusCode)
+		}
+	}
+
+	get()
+	get()
+	if got := atomic.LoadInt32(&srv.issued); got != 1 {
+		t.Fatalf("tokens issued = %d before revocation, want 1", got)
+	}
+
+	// The revoked token is dropped on the 401 and a new one fetched once
+	srv.revoke()
+	get()
+	if got := atomic.LoadInt32(&srv.issued); got != 2 {
+		t.Fatalf("tokens issued = %d after revocation, want 2", got)
+	}
+}
+
+func TestClientCredentialsRefetchesOnce(t *testing.T) {
+	var issued int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			atomic.AddInt32(&issued, 1)
+			w.Write([]byte(`{"access_token":"t"}`))
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+	rg := New(nil).WithClientCredentials(srv.URL+"/token", "id", "secret", nil)
+
+	resp, err := rg.Get(srv.URL + "/api")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", resp.StatusCode)
+	}
+	if got := atomic.LoadInt32(&issued); got != 2 {
+		t.Fatalf("tokens issued = %d, want 2", got)
+	}
+}
+
+func TestClientCredentialsTokenEndpointUnauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":"invalid_client"}`))
+	}))
+	defer srv.Close()
+	rg := New(nil).WithClientCredentials(srv.URL+"/token", "id", "secret", nil)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := rg.Get(srv.URL + "/api")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		var tokenErr *TokenError
+		if !errors.As(err, &tokenErr) || tokenErr.Code != "invalid_client" {
+			t.Fatalf("err = %v, want a *TokenError with invalid_client", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("a 401 from the token endpoint hung the request")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"strings"
 
@@ -23,6 +24,112 @@ func ParseJSON(data []byte) (map[string]interface{}, error) {
     return result, nil
 }
 
+// Decode checks the result of a RushGo request method, decodes the JSON body
+// into a T and closes the body, e.g. rushgo.Decode[User](rg.Get(url)).
+// A non-2xx status is returned as a *StatusError.
+func Decode[T any](resp *http.Response, err error) (T, error) {
+    var result T
+    if err != nil {
+        return result, err
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        return result, newStatusError(resp)
+    }
+
+    if err := clientFromResponse(resp).decodeJSON(resp.Body, &result); err != nil {
+        return result, fmt.Errorf("failed to decode JSON response: %w", err)
+    }
+
+    return result, nil
+}
+
+// DecodeJSON decodes the JSON body of resp into v and closes the body. It
+// returns a *StatusError for a non-2xx status, and an error if the
+// Content-Type is not JSON (application/json or a +json type) or the body is
+// empty. A response without a Content-Type is decoded anyway.
+func DecodeJSON(resp *http.Response, v interface{}) error {
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        return newStatusError(resp)
+    }
+
+    if contentType := resp.Header.Get("Content-Type"); contentType != "" && !isJSONContentType(contentType) {
+        return fmt.Errorf("failed to decode JSON response: unexpected Content-Type %q", contentType)
+    }
+
+    data, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return fmt.Errorf("failed to read response body: %w", err)
+    }
+    if len(bytes.TrimSpace(data)) == 0 {
+        return fmt.Errorf("failed to decode JSON response: empty body")
+    }
+
+    if err := clientFromResponse(resp).decodeJSON(bytes.NewReader(data), v); err != nil {
+        return fmt.Errorf("failed to decode JSON response: %w", err)
+    }
+    return nil
+}
+
+// GetDecode makes a GET request and decodes the body into out according to
+// the Content-Type of the response: JSON (as DecodeJSON does), XML
+// (application/xml, text/xml or a +xml type) or a URL-encoded form. A form
+// can only be decoded into a *url.Values, *map[string][]string or
+// *map[string]string. Any other Content-Type, or none, is an error. A non-2xx
+// status is returned as a *StatusError.
+func (rg *RushGo) GetDecode(url string, out interface{}) error {
+    resp, err := rg.do("GET", url, nil, &requestOptions{headers: map[string]string{
+        "Accept": "application/json, application/xml;q=0.9, application/x-www-form-urlencoded;q=0.8",
+    }})
+    if err != nil {
+        return err
+    }
+
+    contentType := resp.Header.Get("Content-Type")
+    mediaType, _, _ := mime.ParseMediaType(contentType)
+    if isJSONContentType(contentType) {
+        return DecodeJSON(resp, out)
+    }
+    defer resp.Body.Close()
+
+    if resp.StatusCode < 200 || resp.StatusCode > 299 {
+        return newStatusError(resp)
+    }
+
+    switch {
+    case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+        decoder := xml.NewDecoder(resp.Body)
+        decoder.CharsetReader = charset.NewReaderLabel
+        if err := decoder.Decode(out); err != nil {
+            return fmt.Errorf("failed to decode XML response: %w", err)
+        }
+        return nil
+    case mediaType == "application/x-www-form-urlencoded":
+        data, err := io.ReadAll(resp.Body)
+        if err != nil {
+            return fmt.Errorf("failed to read response body: %w", err)
+        }
+        return decodeForm(data, out)
+    case contentType == "":
+        return fmt.Errorf("failed to decode response: no Content-Type")
+    default:
+        return fmt.Errorf("failed to decode response: unsupported Content-Type %q", contentType)
+    }
+}
+
+// isJSONContentType reports whether contentType is application/json, text/json
+// or a structured +json type such as application/problem+json
+func isJSONContentType(contentType string) bool {
+    mediaType, _, err := mime.ParseMediaType(contentType)
+    if err != nil {
+        return false
+    }
+    return mediaType == "application/json" || mediaType == "text/json" || strings.HasSuffix(mediaType, "+json")
+}
+
 // ParseCookies extracts and parses cookies from an http.Response and returns them as a map
 func ParseCookies(resp *http.Response) map[string]string {
     cookies := make(map[string]string)
@@ -34,6 +141,26 @@ func ParseCookies(resp *http.Response) map[string]string {
     return cookies
 }
 
+// ResponseCookies returns the cookies set by resp's Set-Cookie headers, with
+// their attributes, for reading them after e.g. a login without a cookie jar.
+// It returns nil for a nil response.
+func ResponseCookies(resp *http.Response) []*http.Cookie {
+    if resp == nil {
+        return nil
+    }
+    return resp.Cookies()
+}
+
+// CookieMap returns the cookies set by resp as a name to value map, like
+// ParseCookies. When a name is set more than once the last value wins. The
+// map is empty for a nil response.
+func CookieMap(resp *http.Response) map[string]string {
+    if resp == nil {
+        return map[string]string{}
+    }
+    return ParseCookies(resp)
+}
+
 
 // largely incomplete XML parser
 func ParseXML(data io.Reader) (map[string]string, error) {
@@ -0,0 +1,63 @@
+package rushgo
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// requestPriority is an RFC 9218 priority signal
+type requestPriority struct {
+	urgency     int // 0 (highest) to 7 (lowest)
+	incremental bool
+}
+
+// priorityFromWeight maps an HTTP/2 style weight (1 to 256, higher is more
+// important) to an RFC 9218 priority
+func priorityFromWeight(weight int, incremental bool) *requestPriority {
+	if weight < 1 {
+		weight = 1
+	}
+	if weight > 256 {
+		weight = 256
+	}
+	return &requestPriority{urgency: 7 - (weight-1)*8/256, incremental: incremental}
+}
+
+func (p *requestPriority) header() string {
+	value := fmt.Sprintf("u=%d", p.urgency)
+	if p.incremental {
+		value += ", i"
+	}
+	return value
+}
+
+// WithRequestPriority sets the default priority of requests, as an HTTP/2 style
+// weight from 1 to 256 where higher is more important. net/http cannot set
+// stream priorities directly, so it is sent as an RFC 9218 Priority header
+// that HTTP/2 and HTTP/3 servers may use to schedule responses. incremental
+// marks responses that are useful while they arrive, e.g. progressive images.
+// It is only a hint, servers are free to ignore it, and plain HTTP/1.1
+// requests do not carry it.
+func (rg *RushGo) WithRequestPriority(weight int, incremental bool) *RushGo {
+	rg.priority = priorityFromWeight(weight, incremental)
+	return rg
+}
+
+// Priority overrides the client's request priority for this request, see
+// WithRequestPriority
+func (b *RequestBuilder) Priority(weight int, incremental bool) *RequestBuilder {
+	b.opts.priority = priorityFromWeight(weight, incremental)
+	return b
+}
+
+// applyPriority adds the Priority header unless the request will use HTTP/1.1,
+// where there is no multiplexing to prioritise
+func (rg *RushGo) applyPriority(req *http.Request, priority *requestPriority) {
+	if priority == nil || req.Header.Get("Priority") != "" || req.URL.Scheme == "http" {
+		return
+	}
+	if transport, ok := rg.client.Transport.(*http.Transport); ok && !transport.ForceAttemptHTTP2 {
+		return
+	}
+	req.Header.Set("Priority", priority.header())
+}
@@ -0,0 +1,196 @@
+package rushgo
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/proxy"
+)
+
+// SetProxy sends requests through the given proxy like WithProxy, but returns
+// an error for an invalid proxy URL or a transport that can't use a proxy. The
+// client is left unchanged in that case.
+func (rg *RushGo) SetProxy(proxyURL string) error {
+	u, err := parseProxyURL(proxyURL)
+	if err != nil {
+		return err
+	}
+	return rg.setProxy(u)
+}
+
+// parseProxyURL parses and validates a proxy URL
+func parseProxyURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", raw, err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("invalid proxy %q: scheme must be http, https or socks5", raw)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q: missing host", raw)
+	}
+	return u, nil
+}
+
+// setProxy installs proxy on a clone of the current transport
+func (rg *RushGo) setProxy(proxy *url.URL) error {
+	if err := rg.installProxy(http.ProxyURL(proxy)); err != nil {
+		return err
+	}
+	rg.proxyURL = proxy
+	rg.proxyPool = nil
+	return nil
+}
+
+// installProxy sets the transport's Proxy function on a clone of the current
+// transport, keeping every other setting
+func (rg *RushGo) installProxy(proxy func(*http.Request) (*url.URL, error)) error {
+	switch transport := rg.client.Transport.(type) {
+	case *http.Transport:
+		clone := transport.Clone()
+		clone.Proxy = proxy
+		transport.CloseIdleConnections()
+		rg.client.Transport = clone
+		return nil
+	case *http3.RoundTripper:
+		return fmt.Errorf("failed to set proxy: HTTP/3 does not support HTTP proxies")
+	default:
+		return fmt.Errorf("failed to set proxy: not supported with custom transport %T", transport)
+	}
+}
+
+// Connect opens a tunnel to host (host:port) through the proxy set with
+// WithProxy, using an HTTP CONNECT request for http and https proxies and the
+// SOCKS5 handshake for socks5 ones, and returns the raw connection for any
+// protocol to be spoken over it. Credentials in the proxy URL, or for HTTP
+// proxies a default Proxy-Authorization header, are sent with the handshake.
+// An https proxy is reached with the client's TLS settings. The handshake is
+// bound by the client timeout.
+func (rg *RushGo) Connect(host string) (net.Conn, error) {
+	if rg.proxyErr != nil {
+		return nil, rg.proxyErr
+	}
+	if rg.proxyURL == nil {
+		return nil, fmt.Errorf("connect requires a proxy, set one with WithProxy")
+	}
+
+	ctx := context.Background()
+	if rg.client.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, rg.client.Timeout)
+		defer cancel()
+	}
+
+	proxyAddr := rg.proxyURL.Host
+	if rg.proxyURL.Port() == "" {
+		port := "80"
+		switch rg.proxyURL.Scheme {
+		case "https":
+			port = "443"
+		case "socks5":
+			port = "1080"
+		}
+		proxyAddr = net.JoinHostPort(rg.proxyURL.Hostname(), port)
+	}
+
+	if rg.proxyURL.Scheme == "socks5" {
+		return rg.connectSOCKS5(ctx, proxyAddr, host)
+	}
+
+	conn, err := rg.dialer.DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach proxy %s: %w", proxyAddr, err)
+	}
+	if rg.proxyURL.Scheme == "https" {
+		cfg := rg.transportTLSConfig()
+		cfg.ServerName = rg.proxyURL.Hostname()
+		cfg.NextProtos = nil // the CONNECT is HTTP/1.1, whatever the transport negotiates
+		tlsConn := tls.Client(conn, cfg)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to reach proxy %s: %w", proxyAddr, err)
+		}
+		conn = tlsConn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: host},
+		Host:   host,
+		Header: make(http.Header),
+	}
+	if rg.proxyURL.User != nil {
+		password, _ := rg.proxyURL.User.Password()
+		credentials := rg.proxyURL.User.Username() + ":" + password
+		req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+	} else if auth := rg.defaultHeader("Proxy-Authorization"); auth != "" {
+		req.Header.Set("Proxy-Authorization", auth)
+	}
+	if userAgent := rg.currentUserAgent(); userAgent != "" {
+		req.Header.Set("User-Agent", userAgent)
+	}
+
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to read CONNECT response: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy refused CONNECT to %s: status code %d", host, resp.StatusCode)
+	}
+
+	conn.SetDeadline(time.Time{})
+	return &bufferedConn{Conn: conn, reader: reader}, nil
+}
+
+// connectSOCKS5 opens a tunnel to host through the SOCKS5 proxy at proxyAddr
+func (rg *RushGo) connectSOCKS5(ctx context.Context, proxyAddr, host string) (net.Conn, error) {
+	var auth *proxy.Auth
+	if rg.proxyURL.User != nil {
+		password, _ := rg.proxyURL.User.Password()
+		auth = &proxy.Auth{User: rg.proxyURL.User.Username(), Password: password}
+	}
+	dialer, err := proxy.SOCKS5("tcp", proxyAddr, auth, rg.dialer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach proxy %s: %w", proxyAddr, err)
+	}
+	conn, err := dialer.(proxy.ContextDialer).DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, fmt.Errorf("proxy refused connection to %s: %w", host, err)
+	}
+	return conn, nil
+}
+
+// bufferedConn serves any bytes the proxy sent right after its CONNECT
+// response before reading from the connection itself
+type bufferedConn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
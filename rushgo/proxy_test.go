@@ -0,0 +1,167 @@
+package rushgo
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+// echoServer accepts connections and echoes what it reads
+func echoServer(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return ln
+}
+
+// socks5Server is a minimal no-auth SOCKS5 proxy supporting CONNECT to an
+// IPv4 address
+func socks5Server(t *testing.T) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSOCKS5(conn)
+		}
+	}()
+	return ln
+}
+
+func serveSOCKS5(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+
+	greeting := make([]byte, 2)
+	if _, err := io.ReadFull(r, greeting); err != nil {
+		return
+	}
+	if _, err := io.ReadFull(r, make([]byte, greeting[1])); err != nil {
+		return
+	}
+	conn.Write([]byte{5, 0})
+
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(r, request); err != nil || request[3] != 1 {
+		return
+	}
+	addr := make([]byte, 6)
+	if _, err := io.ReadFull(r, addr); err != nil {
+		return
+	}
+	target := net.JoinHostPort(net.IP(addr[:4]).String(), strconv.Itoa(int(binary.BigEndian.Uint16(addr[4:]))))
+	upstream, err := net.Dial("tcp", target)
+	if err != nil {
+		conn.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+		return
+	}
+	defer upstream.Close()
+	conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+
+	go func() {
+		io.Copy(upstream, r)
+		upstream.Close()
+	}()
+	io.Copy(conn, upstream)
+}
+
+// assertEcho checks conn talks to an echo server
+func assertEcho(t *testing.T, conn net.Conn) {
+	t.Helper()
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	reply := make([]byte, 4)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if string(reply) != "ping" {
+		t.Errorf("reply = %q, want ping", reply)
+	}
+}
+
+func TestConnectSOCKS5(t *testing.T) {
+	echo := echoServer(t)
+	defer echo.Close()
+	proxy := socks5Server(t)
+	defer proxy.Close()
+
+	rg := New(nil).WithProxy("socks5://" + proxy.Addr().String())
+	conn, err := rg.Connect(echo.Addr().String())
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer conn.Close()
+	assertEcho(t, conn)
+}
+
+func TestConnectHTTPSProxyUsesClientTLSConfig(t *testing.T) {
+	echo := echoServer(t)
+	defer echo.Close()
+
+	proxy := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "CONNECT only", http.StatusMethodNotAllowed)
+			return
+		}
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		defer upstream.Close()
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		go func() {
+			io.Copy(upstream, buf)
+			upstream.Close()
+		}()
+		io.Copy(conn, upstream)
+	}))
+	defer proxy.Close()
+
+	// The proxy's certificate is only trusted through WithRootCAs
+	rg := New(nil)
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: proxy.Certificate().Raw})
+	if err := rg.WithRootCAs(caPEM); err != nil {
+		t.Fatalf("WithRootCAs: %v", err)
+	}
+	rg.WithProxy(proxy.URL)
+
+	conn, err := rg.Connect(echo.Addr().String())
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer conn.Close()
+	assertEcho(t, conn)
+}
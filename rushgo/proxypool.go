@@ -0,0 +1,127 @@
+package rushgo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// proxyFailureLimit is how many consecutive connection failures take a proxy
+// out of rotation for failoverCooldown
+const proxyFailureLimit = 3
+
+// proxyPool rotates requests across proxies round-robin
+type proxyPool struct {
+	mu      sync.Mutex
+	proxies []*pooledProxy
+	next    int
+}
+
+type pooledProxy struct {
+	url       *url.URL
+	failures  int       // consecutive connection failures
+	downUntil time.Time // out of rotation until then
+}
+
+type proxyChoiceKey struct{}
+
+// proxyChoice records which proxy a single attempt went through
+type proxyChoice struct {
+	proxy *pooledProxy
+}
+
+// WithProxyPool rotates outgoing requests round-robin across proxies, to
+// spread load and stay under per-proxy rate limits. A proxy that fails to
+// connect 3 times in a row is left out of rotation for 30 seconds; if every
+// proxy is out, the one due back soonest is used. The proxy each request went
+// through is reported by Meta. Every URL is validated and an error is returned
+// for the first invalid one, leaving the client unchanged. Like WithProxy it
+// keeps the current transport's settings and is not supported with HTTP/3.
+func (rg *RushGo) WithProxyPool(proxies []string) error {
+	if len(proxies) == 0 {
+		return fmt.Errorf("proxy pool must not be empty")
+	}
+	pool := &proxyPool{}
+	for _, raw := range proxies {
+		u, err := parseProxyURL(raw)
+		if err != nil {
+			return err
+		}
+		pool.proxies = append(pool.proxies, &pooledProxy{url: u})
+	}
+
+	if err := rg.installProxy(pool.proxyFor); err != nil {
+		return err
+	}
+	rg.proxyPool = pool
+	rg.proxyURL = nil
+	rg.proxyErr = nil
+	return nil
+}
+
+// proxyFor is the transport's Proxy function. The transport may ask more than
+// once per attempt, so the first choice is kept for the whole attempt.
+func (p *proxyPool) proxyFor(req *http.Request) (*url.URL, error) {
+	choice, _ := req.Context().Value(proxyChoiceKey{}).(*proxyChoice)
+	if choice != nil && choice.proxy != nil {
+		return choice.proxy.url, nil
+	}
+
+	proxy := p.pick()
+	if choice != nil {
+		choice.proxy = proxy
+	}
+	if meta := metaFromContext(req.Context()); meta != nil {
+		meta.Proxy = proxy.url.Redacted()
+	}
+	return proxy.url, nil
+}
+
+// pick returns the next proxy in rotation
+func (p *proxyPool) pick() *pooledProxy {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var soonest *pooledProxy
+	for i := 0; i < len(p.proxies); i++ {
+		proxy := p.proxies[(p.next+i)%len(p.proxies)]
+		if !now.Before(proxy.downUntil) {
+			p.next = (p.next + i + 1) % len(p.proxies)
+			return proxy
+		}
+		if soonest == nil || proxy.downUntil.Before(soonest.downUntil) {
+			soonest = proxy
+		}
+	}
+	return soonest
+}
+
+// track wraps send so the outcome of each attempt is credited to its proxy
+func (p *proxyPool) track(send func(*http.Request) (*http.Response, error)) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		choice := &proxyChoice{}
+		resp, err := send(req.WithContext(context.WithValue(req.Context(), proxyChoiceKey{}, choice)))
+		if choice.proxy != nil && !isCanceled(err) {
+			p.report(choice.proxy, err == nil)
+		}
+		return resp, err
+	}
+}
+
+func (p *proxyPool) report(proxy *pooledProxy, ok bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ok {
+		proxy.failures = 0
+		return
+	}
+	proxy.failures++
+	if proxy.failures >= proxyFailureLimit {
+		proxy.failures = 0
+		proxy.downUntil = time.Now().Add(failoverCooldown)
+	}
+}
@@ -0,0 +1,157 @@
+package rushgo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// RangePart is one byte range returned by GetRanges
+type RangePart struct {
+	Start, End  int64 // inclusive offsets of Data in the resource
+	Total       int64 // size of the whole resource, -1 if the server didn't say
+	ContentType string
+	Data        []byte
+}
+
+// GetRanges fetches several byte ranges of url in a single request, sending
+// them all in one Range header and parsing the multipart/byteranges reply.
+// Each range is {start, end} with end inclusive; a negative end means up to
+// the end of the resource. The parts come back in the order of ranges, one
+// per range, even if the server merged or reordered them. If the server
+// ignores the multi-range request and sends the full body, or leaves some
+// ranges out, the missing ones are fetched one at a time with GetRange.
+func (rg *RushGo) GetRanges(url string, ranges [][2]int64) ([]RangePart, error) {
+	if len(ranges) == 0 {
+		return nil, errors.New("no ranges requested")
+	}
+	specs := make([]string, len(ranges))
+	for i, r := range ranges {
+		if r[0] < 0 || (r[1] >= 0 && r[1] < r[0]) {
+			return nil, fmt.Errorf("invalid range %d-%d", r[0], r[1])
+		}
+		specs[i] = strconv.FormatInt(r[0], 10) + "-"
+		if r[1] >= 0 {
+			specs[i] += strconv.FormatInt(r[1], 10)
+		}
+	}
+
+	resp, err := rg.do("GET", url, nil, &requestOptions{headers: map[string]string{"Range": "bytes=" + strings.Join(specs, ",")}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var received []RangePart
+	switch {
+	case resp.StatusCode == http.StatusPartialContent:
+		if received, err = readRangeParts(resp); err != nil {
+			return nil, err
+		}
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return nil, newStatusError(resp)
+	}
+	// Anything else is the full body, so fall through to single requests
+
+	parts := make([]RangePart, len(ranges))
+	for i, r := range ranges {
+		part, ok := sliceRangePart(received, r[0], r[1])
+		if !ok {
+			if part, err = rg.getSingleRange(url, r[0], r[1]); err != nil {
+				return nil, err
+			}
+		}
+		parts[i] = part
+	}
+	return parts, nil
+}
+
+// readRangeParts reads a 206 response, which is multipart/byteranges for
+// several ranges or a plain body with Content-Range for one
+func readRangeParts(resp *http.Response) ([]RangePart, error) {
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/byteranges" {
+		part, err := readRangePart(resp.Header.Get("Content-Range"), resp.Header.Get("Content-Type"), resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return []RangePart{part}, nil
+	}
+	if params["boundary"] == "" {
+		return nil, errors.New("failed to parse range response: multipart/byteranges without a boundary")
+	}
+
+	var parts []RangePart
+	reader := multipart.NewReader(resp.Body, params["boundary"])
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse range response: %w", err)
+		}
+		part, err := readRangePart(p.Header.Get("Content-Range"), p.Header.Get("Content-Type"), p)
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+	}
+}
+
+// readRangePart reads one range body and checks it against its Content-Range
+func readRangePart(contentRange, contentType string, body io.Reader) (RangePart, error) {
+	start, end, total, ok := parseContentRange(contentRange)
+	if !ok || start < 0 {
+		return RangePart{}, fmt.Errorf("failed to parse range response: bad Content-Range %q", contentRange)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return RangePart{}, err
+	}
+	if int64(len(data)) != end-start+1 {
+		return RangePart{}, fmt.Errorf("failed to parse range response: range %d-%d has %d bytes", start, end, len(data))
+	}
+	return RangePart{Start: start, End: end, Total: total, ContentType: contentType, Data: data}, nil
+}
+
+// sliceRangePart finds a received part covering start-end and cuts the range
+// out of it
+func sliceRangePart(parts []RangePart, start, end int64) (RangePart, bool) {
+	for _, p := range parts {
+		want := end
+		if want < 0 {
+			// Open-ended, so the part has to run to the end of the resource
+			switch {
+			case p.Total >= 0:
+				want = p.Total - 1
+			case p.Start == start:
+				want = p.End
+			default:
+				continue
+			}
+		}
+		if p.Start > start || p.End < want {
+			continue
+		}
+		p.Data = p.Data[start-p.Start : want-p.Start+1]
+		p.Start, p.End = start, want
+		return p, true
+	}
+	return RangePart{}, false
+}
+
+// getSingleRange fetches one range with GetRange
+func (rg *RushGo) getSingleRange(url string, start, end int64) (RangePart, error) {
+	resp, err := rg.GetRange(url, start, end)
+	if err != nil {
+		return RangePart{}, err
+	}
+	defer resp.Body.Close()
+	return readRangePart(resp.Header.Get("Content-Range"), resp.Header.Get("Content-Type"), resp.Body)
+}
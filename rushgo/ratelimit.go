@@ -0,0 +1,259 @@
+package rushgo
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket refilled at rate tokens per second. With
+// adaptive set, the refill also slows to the rate the server's rate-limit
+// headers allow, and stops until pauseUntil once the server reports none left.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // configured with WithRateLimit, 0 for none
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	adaptive   bool
+	serverRate float64 // from the last rate-limit headers, 0 for none
+	pauseUntil time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// currentRate is the lower of the configured and server rates, 0 when
+// neither applies. The caller holds mu.
+func (l *rateLimiter) currentRate() float64 {
+	if l.serverRate > 0 && (l.rate <= 0 || l.serverRate < l.rate) {
+		return l.serverRate
+	}
+	return l.rate
+}
+
+// wait blocks until a token is available or ctx is done
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		rate := l.currentRate()
+		var delay time.Duration
+		switch {
+		case now.Before(l.pauseUntil):
+			delay = l.pauseUntil.Sub(now)
+		case rate <= 0:
+			l.last = now
+			l.mu.Unlock()
+			return nil
+		default:
+			l.tokens += now.Sub(l.last).Seconds() * rate
+			if l.tokens > l.burst {
+				l.tokens = l.burst
+			}
+			l.last = now
+
+			if l.tokens >= 1 {
+				l.tokens--
+				l.mu.Unlock()
+				return nil
+			}
+			delay = time.Duration((1 - l.tokens) / rate * float64(time.Second))
+		}
+		l.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// adaptiveThreshold is the share of the server's limit below which adaptive
+// throttling starts spreading the remaining requests over the window
+const adaptiveThreshold = 0.2
+
+// adapt updates the server rate from the rate-limit headers of a response
+func (l *rateLimiter) adapt(info *RateLimit) {
+	if info == nil || info.Remaining < 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if info.Reset.IsZero() || !info.Reset.After(now) {
+		// Without a reset time there is no window to spread requests over
+		l.serverRate = 0
+		return
+	}
+	if info.Remaining == 0 {
+		l.pauseUntil = info.Reset
+		return
+	}
+	if info.Limit > 0 && float64(info.Remaining) >= float64(info.Limit)*adaptiveThreshold {
+		l.serverRate = 0
+		return
+	}
+	l.serverRate = float64(info.Remaining) / info.Reset.Sub(now).Seconds()
+}
+
+// observe feeds the rate-limit headers of every response to adapt
+func (l *rateLimiter) observe(send func(*http.Request) (*http.Response, error)) func(*http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := send(req)
+		if err == nil {
+			l.adapt(RateLimitInfo(resp))
+		}
+		return resp, err
+	}
+}
+
+// WithRateLimit limits the client to requestsPerSecond with bursts of up to
+// burst requests. Every request that goes to the network takes a token,
+// including retries, the resend after a reauth and each base URL tried during
+// failover, so retries cannot be used to get around the limit. Waiting for a
+// token stops when the request's context is done. A rate of zero or less
+// removes the limit, leaving only WithAdaptiveThrottle if it is on.
+func (rg *RushGo) WithRateLimit(requestsPerSecond float64, burst int) *RushGo {
+	adaptive := rg.rateLimiter != nil && rg.rateLimiter.adaptive
+	if requestsPerSecond <= 0 {
+		if !adaptive {
+			rg.rateLimiter = nil
+			return rg
+		}
+		requestsPerSecond = 0
+	}
+	rg.rateLimiter = newRateLimiter(requestsPerSecond, burst)
+	rg.rateLimiter.adaptive = adaptive
+	return rg
+}
+
+// WithAdaptiveThrottle paces the client by the rate-limit headers servers
+// send back, in any of the conventions RateLimitInfo understands
+// (X-RateLimit-*, X-Rate-Limit-* and the IETF RateLimit headers). Once the
+// remaining count drops below a fifth of the limit (or always, if the limit
+// isn't sent), the remaining requests are spread evenly until the reset time;
+// at zero remaining, requests wait for the reset. A rate set with
+// WithRateLimit still applies as an upper bound. A response without a reset
+// time lifts an earlier slowdown, one without rate-limit headers changes
+// nothing. The pace is shared by all hosts the client talks to.
+func (rg *RushGo) WithAdaptiveThrottle() *RushGo {
+	if rg.rateLimiter == nil {
+		rg.rateLimiter = newRateLimiter(0, 1)
+	}
+	rg.rateLimiter.adaptive = true
+	return rg
+}
+
+// RateLimit is the rate-limit state a server reported on a response
+type RateLimit struct {
+	Limit     int64     // requests allowed in the window, -1 if not sent
+	Remaining int64     // requests left in the window, -1 if not sent
+	Reset     time.Time // when the window resets, zero if not sent
+}
+
+// RateLimitInfo parses the rate-limit headers of resp. It understands the
+// X-RateLimit-* headers used by GitHub and most APIs (and Twitter's
+// X-Rate-Limit-* spelling), where the reset is either a Unix timestamp or a
+// number of seconds, as well as the IETF draft headers: RateLimit-Limit,
+// RateLimit-Remaining and RateLimit-Reset, the combined
+// "RateLimit: limit=100, remaining=50, reset=30" form and the newer
+// "RateLimit: r=50;t=30" with RateLimit-Policy. The draft headers are
+// preferred when both are sent. It returns nil if resp has none of them.
+func RateLimitInfo(resp *http.Response) *RateLimit {
+	if resp == nil {
+		return nil
+	}
+	info := &RateLimit{Limit: -1, Remaining: -1}
+	found := false
+	set := func(field *int64, value string) {
+		if n, ok := leadingInt(value); ok && *field < 0 {
+			*field = n
+			found = true
+		}
+	}
+	setReset := func(value string) {
+		if n, ok := leadingInt(value); ok && info.Reset.IsZero() {
+			info.Reset = resetTime(n)
+			found = true
+		}
+	}
+
+	// Combined draft header: "limit=100, remaining=50, reset=30" or
+	// "default";r=50;t=30
+	if value := resp.Header.Get("RateLimit"); value != "" {
+		for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+			name, val, _ := strings.Cut(strings.TrimSpace(item), "=")
+			switch strings.ToLower(name) {
+			case "limit":
+				set(&info.Limit, val)
+			case "remaining", "r":
+				set(&info.Remaining, val)
+			case "reset", "t":
+				setReset(val)
+			}
+		}
+	}
+	if value := resp.Header.Get("RateLimit-Policy"); value != "" {
+		for _, item := range strings.Split(value, ";") {
+			if name, val, _ := strings.Cut(strings.TrimSpace(item), "="); strings.EqualFold(name, "q") {
+				set(&info.Limit, val)
+			}
+		}
+	}
+
+	for _, prefix := range []string{"RateLimit-", "X-RateLimit-", "X-Rate-Limit-"} {
+		set(&info.Limit, resp.Header.Get(prefix+"Limit"))
+		set(&info.Remaining, resp.Header.Get(prefix+"Remaining"))
+		setReset(resp.Header.Get(prefix + "Reset"))
+	}
+
+	if !found {
+		return nil
+	}
+	return info
+}
+
+// leadingInt parses the integer at the start of value, ignoring anything after
+// a comma or semicolon, so "100, 100;w=60" gives 100
+func leadingInt(value string) (int64, bool) {
+	value = strings.TrimSpace(value)
+	if end := strings.IndexAny(value, ",;"); end >= 0 {
+		value = strings.TrimSpace(value[:end])
+	}
+	if value == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	return n, err == nil && n >= 0
+}
+
+// resetTime turns a reset value into a time. Values too large to be a
+// window length are Unix timestamps, in seconds or milliseconds.
+func resetTime(n int64) time.Time {
+	switch {
+	case n >= 1e12:
+		return time.UnixMilli(n)
+	case n >= 1e9:
+		return time.Unix(n, 0)
+	default:
+		return time.Now().Add(time.Duration(n) * time.Second)
+	}
+}
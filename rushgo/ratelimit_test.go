@@ -0,0 +1,51 @@
+package rushgo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimitAppliesToRetries(t *testing.T) {
+	const failures = 3
+	var mu sync.Mutex
+	var arrivals []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		n := len(arrivals)
+		mu.Unlock()
+		if n <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	// One token every 100ms and no burst, with a backoff far below that, so
+	// only the limiter can space the attempts out
+	const interval = 100 * time.Millisecond
+	rg := New(nil).WithRateLimit(float64(time.Second/interval), 1).WithRetry(failures, time.Millisecond)
+
+	resp, err := rg.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200 after %d retries", resp.StatusCode, failures)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(arrivals) != failures+1 {
+		t.Fatalf("server saw %d attempts, want %d", len(arrivals), failures+1)
+	}
+	for i := 1; i < len(arrivals); i++ {
+		// Allow some slack for timer granularity
+		if gap := arrivals[i].Sub(arrivals[i-1]); gap < interval*8/10 {
+			t.Errorf("attempt %d came %v after the previous one, want at least %v", i+1, gap, interval)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package rushgo
+
+import "sync"
+
+// reauthState coordinates WithReauth across concurrent requests, so one 401
+// runs the callback and the rest wait for it
+type reauthState struct {
+	mu      sync.Mutex
+	gen     uint64      // number of refreshes finished
+	running *reauthCall // refresh in progress, nil if none
+}
+
+type reauthCall struct {
+	done chan struct{}
+	err  error
+}
+
+// snapshot returns the refresh count and whether a refresh is running, to be
+// taken just before a request is sent
+func (s *reauthState) snapshot() (gen uint64, refreshing bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gen, s.running != nil
+}
+
+// completedSince reports whether a refresh finished after snapshot returned gen
+func (s *reauthState) completedSince(gen uint64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gen != gen
+}
+
+// refresh makes sure a refresh has run since gen. If one already finished the
+// request can just be retried, if one is running it is waited for, and
+// otherwise reauth is called.
+func (s *reauthState) refresh(gen uint64, reauth func() error) error {
+	s.mu.Lock()
+	if s.gen != gen {
+		s.mu.Unlock()
+		return nil
+	}
+	if call := s.running; call != nil {
+		s.mu.Unlock()
+		<-call.done
+		return call.err
+	}
+	call := &reauthCall{done: make(chan struct{})}
+	s.running = call
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.gen++
+		s.running = nil
+		s.mu.Unlock()
+		close(call.done)
+	}()
+	call.err = reauth()
+	return call.err
+}
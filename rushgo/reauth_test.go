@@ -0,0 +1,80 @@
+package rushgo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReauthConcurrent401s(t *testing.T) {
+	const workers = 10
+
+	// Hold the first round of requests until all of them are in flight, so
+	// every worker is sent with the stale token before any 401 comes back
+	var arrived int32
+	allSent := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/data" && r.Header.Get("Authorization") == "Bearer stale" {
+			if atomic.AddInt32(&arrived, 1) == workers {
+				close(allSent)
+			}
+			<-allSent
+		}
+		if r.URL.Path == "/login" || r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var calls int32
+	rg := New(nil).WithBearerToken("stale")
+	rg.WithReauth(func(rg *RushGo) error {
+		atomic.AddInt32(&calls, 1)
+		// A login call answered with 401 must come straight back
+		resp, err := rg.Get(srv.URL + "/login")
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("login status = %d, want 401", resp.StatusCode)
+		}
+		time.Sleep(50 * time.Millisecond)
+		rg.WithBearerToken("fresh")
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	statuses := make([]int, workers)
+	errs := make([]error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := rg.Get(srv.URL + "/data")
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			resp.Body.Close()
+			statuses[i] = resp.StatusCode
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < workers; i++ {
+		if errs[i] != nil {
+			t.Errorf("request %d: %v", i, errs[i])
+		} else if statuses[i] != http.StatusOK {
+			t.Errorf("request %d: status %d, want 200", i, statuses[i])
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("reauth called %d times, want 1", n)
+	}
+}
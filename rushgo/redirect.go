@@ -0,0 +1,82 @@
+package rushgo
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// ErrTooManyRedirects is returned when a request is redirected more times
+// than WithMaxRedirects allows
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// defaultMaxRedirects matches net/http, which is what a client gets before
+// any redirect option is set
+const defaultMaxRedirects = 10
+
+// redirectPolicy decides which redirects the client follows
+type redirectPolicy struct {
+	max       int  // hops to follow, 0 for none, -1 for no limit
+	stripAuth bool // drop Authorization when the origin changes
+}
+
+// WithMaxRedirects follows at most n redirects per request and fails with an
+// error wrapping ErrTooManyRedirects on the next one, so a redirect loop fails
+// fast instead of running into the timeout. A limit of 0 turns redirects off
+// like DisableRedirects, and a negative one removes the limit.
+func (rg *RushGo) WithMaxRedirects(n int) *RushGo {
+	if n < 0 {
+		n = -1
+	}
+	rg.redirects().max = n
+	return rg
+}
+
+// DisableRedirects stops the client from following redirects: the 3xx
+// response itself is returned, with its Location header, and its body can be
+// read as usual
+func (rg *RushGo) DisableRedirects() *RushGo {
+	return rg.WithMaxRedirects(0)
+}
+
+// WithStrictRedirectAuth drops the Authorization header when a redirect leaves
+// the origin (scheme, host and port) of the original request. net/http already
+// drops it for other domains but keeps it for subdomains and for a redirect
+// from https to plain http on the same host, both of which can leak
+// credentials.
+func (rg *RushGo) WithStrictRedirectAuth() *RushGo {
+	rg.redirects().stripAuth = true
+	return rg
+}
+
+// redirects returns the client's redirect policy, installing the default one
+// on first use
+func (rg *RushGo) redirects() *redirectPolicy {
+	if rg.redirectPolicy == nil {
+		rg.redirectPolicy = &redirectPolicy{max: defaultMaxRedirects}
+		rg.client.CheckRedirect = rg.redirectPolicy.check
+	}
+	return rg.redirectPolicy
+}
+
+// check is the client's CheckRedirect. via holds the requests made so far, so
+// len(via) is the number of the hop about to be followed.
+func (p *redirectPolicy) check(req *http.Request, via []*http.Request) error {
+	if p.max == 0 {
+		return http.ErrUseLastResponse
+	}
+	if p.max > 0 && len(via) > p.max {
+		return fmt.Errorf("stopped after %d redirects: %w", p.max, ErrTooManyRedirects)
+	}
+	if p.stripAuth && !sameOrigin(req.URL, via[0].URL) {
+		req.Header.Del("Authorization")
+	}
+	return nil
+}
+
+// sameOrigin reports whether a and b have the same scheme, host and port,
+// treating an omitted default port as given
+func sameOrigin(a, b *url.URL) bool {
+	return normalizeURLKey(a.Scheme+"://"+a.Host) == normalizeURLKey(b.Scheme+"://"+b.Host)
+}
@@ -0,0 +1,64 @@
+package rushgo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// maxResolveRedirects is how many redirects ResolveHeaders follows
+const maxResolveRedirects = 10
+
+// ResolveHeaders follows redirects from rawURL with HEAD requests and returns
+// the headers and URL of the final response, without downloading any body,
+// e.g. to check a link's Content-Type and size. Servers that reject HEAD (405
+// or 501) are asked with a GET whose body is discarded unread. At most 10
+// redirects are followed, and the whole chain is bound by the client timeout.
+func (rg *RushGo) ResolveHeaders(rawURL string) (http.Header, string, error) {
+	ctx := context.Background()
+	if rg.client.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, rg.client.Timeout)
+		defer cancel()
+	}
+
+	current := rawURL
+	for hop := 0; hop <= maxResolveRedirects; hop++ {
+		resp, err := rg.resolveHop(ctx, current)
+		if err != nil {
+			return nil, "", err
+		}
+		resp.Body.Close()
+
+		location := resp.Header.Get("Location")
+		if resp.StatusCode < 300 || resp.StatusCode > 399 || location == "" || resp.StatusCode == http.StatusNotModified {
+			return resp.Header, current, nil
+		}
+
+		base, err := url.Parse(current)
+		if err != nil {
+			return nil, "", err
+		}
+		next, err := base.Parse(location)
+		if err != nil {
+			return nil, "", fmt.Errorf("invalid redirect location %q: %w", location, err)
+		}
+		current = next.String()
+	}
+	return nil, "", fmt.Errorf("failed to resolve %s: stopped after %d redirects", rawURL, maxResolveRedirects)
+}
+
+// resolveHop requests url without following redirects, falling back to GET
+// when HEAD is not allowed
+func (rg *RushGo) resolveHop(ctx context.Context, url string) (*http.Response, error) {
+	resp, err := rg.do("HEAD", url, nil, &requestOptions{ctx: ctx, noRedirects: true})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusMethodNotAllowed && resp.StatusCode != http.StatusNotImplemented {
+		return resp, nil
+	}
+	resp.Body.Close()
+	return rg.do("GET", url, nil, &requestOptions{ctx: ctx, noRedirects: true})
+}
@@ -0,0 +1,200 @@
+package rushgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+// UploadProtocol is the server protocol UploadResumable speaks
+type UploadProtocol interface {
+	// Offset asks the server how many of the total bytes it already has
+	Offset(rg *RushGo, url string, total int64) (int64, error)
+	// AppendRequest returns the method and headers for a request whose body
+	// is the file from offset to the end
+	AppendRequest(offset, total int64) (method string, headers map[string]string)
+}
+
+// RangeUploadProtocol is the default UploadProtocol. The current offset is the
+// Upload-Offset header of a HEAD response, falling back to its Content-Length,
+// and 0 when the resource does not exist yet. The rest of the file is sent
+// with a PATCH carrying a Content-Range header.
+type RangeUploadProtocol struct{}
+
+func (RangeUploadProtocol) Offset(rg *RushGo, url string, total int64) (int64, error) {
+	resp, err := rg.Head(url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return 0, nil
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return 0, newStatusError(resp)
+	}
+	if value := resp.Header.Get("Upload-Offset"); value != "" {
+		return strconv.ParseInt(value, 10, 64)
+	}
+	if resp.ContentLength > 0 {
+		return resp.ContentLength, nil
+	}
+	return 0, nil
+}
+
+func (RangeUploadProtocol) AppendRequest(offset, total int64) (string, map[string]string) {
+	contentRange := fmt.Sprintf("bytes %d-%d/%d", offset, total-1, total)
+	if total == 0 {
+		contentRange = "bytes */0"
+	}
+	return "PATCH", map[string]string{
+		"Content-Type":  "application/octet-stream",
+		"Content-Range": contentRange,
+	}
+}
+
+// TusUploadProtocol speaks the core tus 1.0.0 protocol (https://tus.io). The
+// URL passed to UploadResumable must be an upload already created on the
+// server, since creation is a separate tus extension.
+type TusUploadProtocol struct{}
+
+func (TusUploadProtocol) Offset(rg *RushGo, url string, total int64) (int64, error) {
+	resp, err := rg.do("HEAD", url, nil, &requestOptions{headers: map[string]string{"Tus-Resumable": "1.0.0"}})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return 0, newStatusError(resp)
+	}
+	return strconv.ParseInt(resp.Header.Get("Upload-Offset"), 10, 64)
+}
+
+func (TusUploadProtocol) AppendRequest(offset, total int64) (string, map[string]string) {
+	return "PATCH", map[string]string{
+		"Tus-Resumable": "1.0.0",
+		"Content-Type":  "application/offset+octet-stream",
+		"Upload-Offset": strconv.FormatInt(offset, 10),
+	}
+}
+
+// WithUploadProtocol sets the protocol UploadResumable uses. The default is
+// RangeUploadProtocol.
+func (rg *RushGo) WithUploadProtocol(protocol UploadProtocol) *RushGo {
+	rg.uploadProtocol = protocol
+	return rg
+}
+
+// UploadResumable uploads the file at filePath to url, first asking the server
+// how much of it it already has and sending only the rest, so an interrupted
+// upload can be picked up by calling it again. If the connection drops it
+// re-queries the offset and continues, up to the WithRetry limit. onProgress,
+// if not nil, is called with the bytes the server has so far and the file
+// size as the upload advances. If the server already has the whole file no
+// upload request is sent and the returned response is nil.
+func (rg *RushGo) UploadResumable(url, filePath string, onProgress func(sent, total int64)) (*http.Response, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("failed to open file %s: is a directory", filePath)
+	}
+	total := info.Size()
+
+	protocol := rg.uploadProtocol
+	if protocol == nil {
+		protocol = RangeUploadProtocol{}
+	}
+
+	for retry := 0; ; retry++ {
+		if retry > 0 {
+			time.Sleep(rg.retryDelay(retry))
+		}
+
+		resp, err := rg.uploadFrom(protocol, url, filePath, total, onProgress)
+		if err == nil || retry >= rg.retryMax || !resumableUploadError(err) {
+			return resp, err
+		}
+	}
+}
+
+// uploadFrom runs one offset query plus append
+func (rg *RushGo) uploadFrom(protocol UploadProtocol, url, filePath string, total int64, onProgress func(sent, total int64)) (*http.Response, error) {
+	offset, err := protocol.Offset(rg, url, total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query upload offset: %w", err)
+	}
+	if offset < 0 || offset > total {
+		return nil, fmt.Errorf("failed to query upload offset: server reported %d of %d bytes", offset, total)
+	}
+	if onProgress != nil {
+		onProgress(offset, total)
+	}
+	if offset == total && total > 0 {
+		return nil, nil
+	}
+
+	method, headers := protocol.AppendRequest(offset, total)
+	resp, err := rg.do(method, url, nil, &requestOptions{
+		headers: headers,
+		getBody: func() (io.ReadCloser, error) {
+			file, err := os.Open(filePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
+			}
+			var body io.Reader = io.NewSectionReader(file, offset, total-offset)
+			if onProgress != nil {
+				body = &progressReader{Reader: body, sent: offset, total: total, onProgress: onProgress}
+			}
+			return struct {
+				io.Reader
+				io.Closer
+			}{body, file}, nil
+		},
+		contentLength: total - offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, newStatusError(resp)
+	}
+	return resp, nil
+}
+
+// resumableUploadError reports whether an upload attempt is worth resuming:
+// connection errors and offset conflicts are, other rejections are not
+func resumableUploadError(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusConflict || statusErr.StatusCode >= 500
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, ErrBodyNotReplayable)
+}
+
+// progressReader reports the absolute upload position as the body is read
+type progressReader struct {
+	io.Reader
+	sent, total int64
+	onProgress  func(sent, total int64)
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.Reader.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.onProgress(p.sent, p.total)
+	}
+	return n, err
+}
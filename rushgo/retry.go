@@ -0,0 +1,134 @@
+package rushgo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrBodyNotReplayable is returned when a request has to be sent again (retry,
+// reauth or base URL failover) but its body was a one-shot stream that has
+// already been consumed and was not buffered
+var ErrBodyNotReplayable = errors.New("request body cannot be replayed")
+
+// WithRetry retries a request up to maxRetries times when it fails with a
+// connection error, a response validator rejects it, or the server answers 429,
+// 502, 503 or 504. The wait before retry n is backoff doubled n-1 times. The
+// download and read helpers (DownloadFile, DownloadImage, ResumeDownload,
+// GetChunked and GetMany) also fetch again, or resume with a Range request,
+// when the connection drops while the body is being read.
+func (rg *RushGo) WithRetry(maxRetries int, backoff time.Duration) *RushGo {
+	rg.retryMax = maxRetries
+	rg.retryBackoff = backoff
+	return rg
+}
+
+// WithRetryBodyBuffering controls what happens to one-shot streaming bodies
+// when a request is retried. When enabled, bodies of up to limit bytes are
+// buffered in memory so they can be resent; larger bodies, or any streaming
+// body when disabled, make a second attempt fail with ErrBodyNotReplayable.
+// Byte-slice and file bodies are always replayable. Buffering is off by default.
+func (rg *RushGo) WithRetryBodyBuffering(enabled bool, limit int64) *RushGo {
+	rg.retryBuffering = enabled
+	rg.retryBufferLimit = limit
+	return rg
+}
+
+// isRetryable reports whether the outcome of an attempt is worth retrying
+func isRetryable(resp *http.Response, err error) bool {
+	if err != nil {
+		var urlErr *url.Error
+		return errors.As(err, &urlErr) &&
+			!errors.Is(err, context.Canceled) &&
+			!errors.Is(err, context.DeadlineExceeded) &&
+			!errors.Is(err, ErrBodyNotReplayable)
+	}
+	return isRetryableStatus(resp.StatusCode)
+}
+
+// isRetryableStatus reports whether a response with the status is retried
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isTruncatedBody reports whether err is a connection drop while reading a
+// response body. The transports don't all wrap io.ErrUnexpectedEOF, so the
+// message is checked as well.
+func isTruncatedBody(err error) bool {
+	return err != nil && (errors.Is(err, io.ErrUnexpectedEOF) || strings.Contains(err.Error(), "unexpected EOF"))
+}
+
+// retryOnTruncation runs fetch again, up to the WithRetry limit, while it fails
+// because the response body was cut off. fetch must undo its own partial
+// state or be able to resume from it. The wait between tries ends early with
+// ctx.Err() when ctx is done; a nil ctx waits on the client's CancelAll only.
+func (rg *RushGo) retryOnTruncation(ctx context.Context, fetch func() error) error {
+	if ctx == nil {
+		ctx = rg.root.get()
+	}
+	for retry := 0; ; retry++ {
+		if retry > 0 {
+			if err := sleepContext(ctx, rg.retryDelay(retry)); err != nil {
+				return err
+			}
+		}
+		err := fetch()
+		if !isTruncatedBody(err) || retry >= rg.retryMax {
+			return err
+		}
+	}
+}
+
+// retryDelay returns the wait before the given retry (1 for the first)
+func (rg *RushGo) retryDelay(retry int) time.Duration {
+	return rg.retryBackoff << uint(retry-1)
+}
+
+// prepareOneShotBody turns opts.bodyReader into a getBody. With buffering
+// enabled and the body within the limit it is held in memory; otherwise the
+// stream can only be handed out once.
+func (rg *RushGo) prepareOneShotBody(opts *requestOptions) error {
+	if opts.bodyReader == nil {
+		return nil
+	}
+	reader := opts.bodyReader
+	opts.bodyReader = nil
+
+	if rg.retryBuffering {
+		buf, err := io.ReadAll(io.LimitReader(reader, rg.retryBufferLimit+1))
+		if err != nil {
+			return err
+		}
+		if int64(len(buf)) <= rg.retryBufferLimit {
+			opts.getBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(buf)), nil
+			}
+			opts.contentLength = int64(len(buf))
+			return nil
+		}
+		reader = io.MultiReader(bytes.NewReader(buf), reader)
+	}
+
+	var once sync.Once
+	opts.getBody = func() (io.ReadCloser, error) {
+		body := io.ReadCloser(nil)
+		once.Do(func() {
+			body = io.NopCloser(reader)
+		})
+		if body == nil {
+			return nil, ErrBodyNotReplayable
+		}
+		return body, nil
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package rushgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// truncatingServer promises 100 bytes of body and drops the connection after 10
+func truncatingServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 100\r\nAccept-Ranges: bytes\r\nETag: \"v1\"\r\n\r\n0123456789")
+		buf.Flush()
+	}))
+}
+
+func TestTruncationRetryHonorsContext(t *testing.T) {
+	srv := truncatingServer(t)
+	defer srv.Close()
+
+	// The backoff is far longer than the test, so only cancellation can end it
+	rg := New(nil).WithRetry(3, time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	savePath := filepath.Join(t.TempDir(), "file")
+	done := make(chan error, 1)
+	go func() {
+		_, err := rg.DownloadFileCtx(ctx, srv.URL, &savePath)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("DownloadFileCtx error = %v, want context.DeadlineExceeded", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("DownloadFileCtx kept waiting after its context expired")
+	}
+}
+
+func TestGetChunkedResumeHonorsCancel(t *testing.T) {
+	srv := truncatingServer(t)
+	defer srv.Close()
+
+	rg := New(nil).WithRetry(3, time.Hour)
+	done := make(chan error, 1)
+	go func() {
+		done <- rg.GetChunked(srv.URL, func([]byte) error { return nil })
+	}()
+	time.Sleep(100 * time.Millisecond)
+	rg.CancelAll()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("GetChunked error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("GetChunked kept waiting after CancelAll")
+	}
+}
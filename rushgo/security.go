@@ -0,0 +1,174 @@
+package rushgo
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// SecurityReport describes the security headers of a single response
+type SecurityReport struct {
+	URL        string
+	StatusCode int
+	Headers    []SecurityHeader // one entry per checked header, in a fixed order
+}
+
+// SecurityHeader is the result of checking one security header
+type SecurityHeader struct {
+	Name    string   // canonical header name, e.g. "Strict-Transport-Security"
+	Present bool     // the header was sent
+	Valid   bool     // the header was sent and is well formed
+	Value   string   // raw header value, empty if missing
+	Issues  []string // problems found, empty when the header is valid and not weakened
+}
+
+// Secure reports whether every checked header is present, valid and has no
+// issues. Expect-CT is optional and only counts when it is sent.
+func (r SecurityReport) Secure() bool {
+	for _, h := range r.Headers {
+		if h.Name == "Expect-Ct" && !h.Present {
+			continue
+		}
+		if !h.Valid || len(h.Issues) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// WithSecurityHeaderCheck evaluates Strict-Transport-Security,
+// Content-Security-Policy, X-Content-Type-Options and Expect-CT on every
+// response and passes the findings to report. The check is purely
+// observational: report is called on its own goroutine and never affects the
+// request.
+func (rg *RushGo) WithSecurityHeaderCheck(report func(report SecurityReport)) *RushGo {
+	rg.securityCheck = report
+	return rg
+}
+
+// checkSecurityHeaders hands a report for resp to the configured callback
+func (rg *RushGo) checkSecurityHeaders(resp *http.Response) {
+	if rg.securityCheck == nil {
+		return
+	}
+	report := SecurityReport{
+		URL:        resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Headers: []SecurityHeader{
+			checkHSTS(resp),
+			checkCSP(resp.Header),
+			checkContentTypeOptions(resp.Header),
+			checkExpectCT(resp.Header),
+		},
+	}
+	go rg.securityCheck(report)
+}
+
+func checkHSTS(resp *http.Response) SecurityHeader {
+	h := SecurityHeader{Name: "Strict-Transport-Security", Value: resp.Header.Get("Strict-Transport-Security")}
+	h.Present = h.Value != ""
+	if resp.Request.URL.Scheme != "https" {
+		// Browsers ignore HSTS over plain HTTP
+		h.Issues = append(h.Issues, "response was not served over HTTPS")
+	}
+	if !h.Present {
+		h.Issues = append(h.Issues, "header missing")
+		return h
+	}
+
+	directives := parseDirectives(h.Value)
+	maxAge, ok := directives["max-age"]
+	if !ok {
+		h.Issues = append(h.Issues, "max-age directive missing")
+		return h
+	}
+	seconds, err := strconv.ParseInt(strings.Trim(maxAge, `"`), 10, 64)
+	if err != nil || seconds < 0 {
+		h.Issues = append(h.Issues, "max-age is not a non-negative integer")
+		return h
+	}
+	h.Valid = true
+	if seconds == 0 {
+		h.Issues = append(h.Issues, "max-age=0 disables HSTS")
+	}
+	return h
+}
+
+func checkCSP(header http.Header) SecurityHeader {
+	h := SecurityHeader{Name: "Content-Security-Policy", Value: header.Get("Content-Security-Policy")}
+	h.Present = h.Value != ""
+	if !h.Present {
+		if header.Get("Content-Security-Policy-Report-Only") != "" {
+			h.Issues = append(h.Issues, "only Content-Security-Policy-Report-Only is set, policy is not enforced")
+		} else {
+			h.Issues = append(h.Issues, "header missing")
+		}
+		return h
+	}
+
+	directives := 0
+	for _, directive := range strings.Split(h.Value, ";") {
+		fields := strings.Fields(directive)
+		if len(fields) == 0 {
+			continue
+		}
+		directives++
+		for _, source := range fields[1:] {
+			switch strings.ToLower(source) {
+			case "'unsafe-inline'", "'unsafe-eval'":
+				h.Issues = append(h.Issues, fields[0]+" allows "+source)
+			case "*":
+				h.Issues = append(h.Issues, fields[0]+" allows any source")
+			}
+		}
+	}
+	if directives == 0 {
+		h.Issues = append(h.Issues, "policy has no directives")
+		return h
+	}
+	h.Valid = true
+	return h
+}
+
+func checkContentTypeOptions(header http.Header) SecurityHeader {
+	h := SecurityHeader{Name: "X-Content-Type-Options", Value: header.Get("X-Content-Type-Options")}
+	h.Present = h.Value != ""
+	switch {
+	case !h.Present:
+		h.Issues = append(h.Issues, "header missing")
+	case !strings.EqualFold(strings.TrimSpace(h.Value), "nosniff"):
+		h.Issues = append(h.Issues, `value must be "nosniff"`)
+	default:
+		h.Valid = true
+	}
+	return h
+}
+
+func checkExpectCT(header http.Header) SecurityHeader {
+	h := SecurityHeader{Name: "Expect-Ct", Value: header.Get("Expect-CT")}
+	h.Present = h.Value != ""
+	if !h.Present {
+		return h
+	}
+
+	maxAge, ok := parseDirectives(h.Value)["max-age"]
+	if _, err := strconv.ParseInt(strings.Trim(maxAge, `"`), 10, 64); !ok || err != nil {
+		h.Issues = append(h.Issues, "max-age directive missing or invalid")
+		return h
+	}
+	h.Valid = true
+	return h
+}
+
+// parseDirectives splits a "name=value; flag" header into lowercased names
+// and their values
+func parseDirectives(value string) map[string]string {
+	directives := map[string]string{}
+	for _, part := range strings.Split(value, ";") {
+		name, val, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			directives[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(val)
+		}
+	}
+	return directives
+}
@@ -0,0 +1,117 @@
+package rushgo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// ErrBodyTooLarge is returned when a response body is bigger than the
+// WithMaxBodySize limit
+var ErrBodyTooLarge = errors.New("response body too large")
+
+// WithMaxBodySize caps how many bytes of a response body the helpers that read
+// it whole into memory (Snapshot, BodyBytes and BodyString) accept. Larger
+// bodies fail with an error wrapping ErrBodyTooLarge. Zero or less, the
+// default, means no limit. It also caps the bodies WithIdempotencyKey shares
+// and the frames GRPCWebUnary reads.
+func (rg *RushGo) WithMaxBodySize(limit int64) *RushGo {
+	rg.maxBodySize = limit
+	return rg
+}
+
+// readBody reads and closes resp.Body, enforcing the WithMaxBodySize limit of
+// the client that made resp
+func readBody(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+
+	var limit int64
+	if rg := clientFromResponse(resp); rg != nil {
+		limit = rg.maxBodySize
+	}
+	if limit <= 0 {
+		return io.ReadAll(resp.Body)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("body exceeds %d bytes: %w", limit, ErrBodyTooLarge)
+	}
+	return data, nil
+}
+
+// BodyBytes reads the whole body of resp and closes it. The WithMaxBodySize
+// limit of the client that made resp applies. A nil resp is an error.
+func BodyBytes(resp *http.Response) ([]byte, error) {
+	if resp == nil || resp.Body == nil {
+		return nil, errors.New("failed to read response body: response is nil")
+	}
+	data, err := readBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return data, nil
+}
+
+// BodyString is BodyBytes returning a string
+func BodyString(resp *http.Response) (string, error) {
+	data, err := BodyBytes(resp)
+	return string(data), err
+}
+
+// ResponseSnapshot is a fully read response that can be handed to any number
+// of consumers. It can't be modified after Snapshot returns it.
+type ResponseSnapshot struct {
+	status     string
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+// Snapshot reads the whole body of resp, closes it and returns the status,
+// headers and body as a ResponseSnapshot. The WithMaxBodySize limit of the
+// client that made resp applies.
+func Snapshot(resp *http.Response) (*ResponseSnapshot, error) {
+	if resp == nil {
+		return nil, errors.New("failed to snapshot response: response is nil")
+	}
+	body, err := readBody(resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return &ResponseSnapshot{
+		status:     resp.Status,
+		statusCode: resp.StatusCode,
+		header:     resp.Header.Clone(),
+		body:       body,
+	}, nil
+}
+
+// StatusCode returns the response status code, e.g. 200
+func (s *ResponseSnapshot) StatusCode() int {
+	return s.statusCode
+}
+
+// Status returns the response status line, e.g. "200 OK"
+func (s *ResponseSnapshot) Status() string {
+	return s.status
+}
+
+// Header returns a copy of the response headers
+func (s *ResponseSnapshot) Header() http.Header {
+	return s.header.Clone()
+}
+
+// Body returns a new reader over the body, starting at the beginning
+func (s *ResponseSnapshot) Body() io.ReadCloser {
+	return io.NopCloser(bytes.NewReader(s.body))
+}
+
+// Bytes returns a copy of the body
+func (s *ResponseSnapshot) Bytes() []byte {
+	return append([]byte(nil), s.body...)
+}
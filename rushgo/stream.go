@@ -0,0 +1,223 @@
+package rushgo
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// defaultChunkSize is the chunk size GetChunked uses unless WithChunkSize is set
+const defaultChunkSize = 32 * 1024
+
+// WithChunkSize sets the size of the chunks GetChunked hands to its callback
+func (rg *RushGo) WithChunkSize(size int) *RushGo {
+	rg.chunkSize = size
+	return rg
+}
+
+// GetChunked makes a GET request and passes the response body to onChunk in
+// fixed-size chunks (the last one may be shorter). The slice is reused between
+// calls, so copy it if it has to outlive the callback. Reading stops at the
+// first error from onChunk, which is returned. A non-2xx response is returned
+// as a *StatusError. The body is always closed. With WithRetry set, a
+// connection drop mid-body is resumed with a Range request if the server
+// supports ranges and sent a strong validator; already delivered bytes are
+// not passed to onChunk again.
+func (rg *RushGo) GetChunked(url string, onChunk func([]byte) error) error {
+	return rg.getChunked(nil, url, onChunk)
+}
+
+// getChunked is GetChunked bound to ctx, nil for none
+func (rg *RushGo) getChunked(ctx context.Context, url string, onChunk func([]byte) error) error {
+	resp, err := rg.do("GET", url, nil, &requestOptions{ctx: ctx})
+	if err != nil {
+		return err
+	}
+	body := resp.Body
+	defer func() { body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newStatusError(resp)
+	}
+
+	size := rg.chunkSize
+	if size <= 0 {
+		size = defaultChunkSize
+	}
+	buf := make([]byte, size)
+	ctx = resp.Request.Context()
+	var delivered int64
+	retries := 0
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Fill the buffer by hand rather than with io.ReadFull, which would
+		// turn a truncated body into a normal short last chunk
+		n := 0
+		var err error
+		for n < len(buf) && err == nil {
+			var m int
+			m, err = body.Read(buf[n:])
+			n += m
+			if isTruncatedBody(err) && retries < rg.retryMax {
+				retries++
+				resumed, resumeErr := rg.resumeBody(ctx, url, resp.Header, delivered+int64(n), retries)
+				switch {
+				case resumeErr == nil:
+					body.Close()
+					body, ctx, err = resumed.Body, resumed.Request.Context(), nil
+				case ctx.Err() != nil:
+					err = ctx.Err()
+				}
+			}
+		}
+
+		if n > 0 {
+			if cbErr := onChunk(buf[:n]); cbErr != nil {
+				return cbErr
+			}
+			delivered += int64(n)
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			return err
+		}
+	}
+}
+
+// defaultMaxLineLength is the longest line GetLines accepts unless
+// WithMaxLineLength is set
+const defaultMaxLineLength = 64 * 1024
+
+// WithMaxLineLength sets the longest line, in bytes, GetLines can handle. Raise
+// it for bodies with very long lines.
+func (rg *RushGo) WithMaxLineLength(size int) *RushGo {
+	rg.maxLineLength = size
+	return rg
+}
+
+// GetLines makes a GET request and passes the response body to onLine one line
+// at a time, without the line ending. Reading stops at the first error from
+// onLine, which is returned, and when the request's context is canceled. A line
+// longer than the WithMaxLineLength limit fails with bufio.ErrTooLong. A
+// non-2xx response is returned as a *StatusError. The body is always closed.
+func (rg *RushGo) GetLines(url string, onLine func(string) error) error {
+	return rg.getLines(nil, url, onLine)
+}
+
+// getLines is GetLines bound to ctx, nil for none
+func (rg *RushGo) getLines(ctx context.Context, url string, onLine func(string) error) error {
+	resp, err := rg.do("GET", url, nil, &requestOptions{ctx: ctx})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return newStatusError(resp)
+	}
+
+	size := rg.maxLineLength
+	if size <= 0 {
+		size = defaultMaxLineLength
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	initial := 4096
+	if size < initial {
+		initial = size
+	}
+	scanner.Buffer(make([]byte, 0, initial), size)
+	ctx = resp.Request.Context()
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := onLine(scanner.Text()); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		if errors.Is(err, bufio.ErrTooLong) {
+			return fmt.Errorf("line longer than %d bytes: %w", size, err)
+		}
+		return err
+	}
+	return ctx.Err()
+}
+
+// resumeBody fetches url again from offset, returning the response for the
+// rest of the body only if the server confirms it is the same representation
+// described by header. The wait before the request is cut short if ctx is done.
+func (rg *RushGo) resumeBody(ctx context.Context, url string, header http.Header, offset int64, retry int) (*http.Response, error) {
+	validator := resumeValidator(header)
+	if header.Get("Accept-Ranges") != "bytes" || validator == "" {
+		return nil, fmt.Errorf("failed to resume body: server does not support ranges")
+	}
+	if err := sleepContext(ctx, rg.retryDelay(retry)); err != nil {
+		return nil, err
+	}
+
+	resp, err := rg.do("GET", url, nil, &requestOptions{ctx: ctx, headers: map[string]string{
+		"Range":    fmt.Sprintf("bytes=%d-", offset),
+		"If-Range": validator,
+	}})
+	if err != nil {
+		return nil, err
+	}
+	if start, _, _, ok := parseContentRange(resp.Header.Get("Content-Range")); resp.StatusCode != http.StatusPartialContent || !ok || start != offset {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to resume body: status code %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// GetWithTee makes a GET request and returns the response with its body teed
+// to tee: everything the caller reads is also written to tee, so a stream can
+// be processed and archived in one pass. A failed write to tee fails the
+// Read. Closing the body flushes tee if it has a Flush() error method and
+// closes it if it is an io.Closer.
+func (rg *RushGo) GetWithTee(url string, tee io.Writer) (*http.Response, error) {
+	resp, err := rg.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &teeReadCloser{Reader: io.TeeReader(resp.Body, tee), body: resp.Body, tee: tee}
+	return resp, nil
+}
+
+// teeReadCloser closes both the response body and the tee writer
+type teeReadCloser struct {
+	io.Reader
+	body io.Closer
+	tee  io.Writer
+}
+
+func (t *teeReadCloser) Close() error {
+	err := t.body.Close()
+	if f, ok := t.tee.(interface{ Flush() error }); ok {
+		if ferr := f.Flush(); ferr != nil && err == nil {
+			err = ferr
+		}
+	}
+	if c, ok := t.tee.(io.Closer); ok {
+		if cerr := c.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,125 @@
+package rushgo
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+// WithTLSConfig replaces the TLS settings of the transport, HTTP/2 or HTTP/3,
+// with a copy of cfg. Call it before the other TLS options, since it discards
+// whatever they set. Open connections are closed so new ones use cfg. It
+// returns an error for a custom transport.
+func (rg *RushGo) WithTLSConfig(cfg *tls.Config) error {
+	return rg.configureTLS(func(*tls.Config) *tls.Config {
+		if cfg == nil {
+			return &tls.Config{}
+		}
+		return cfg.Clone()
+	})
+}
+
+// WithInsecureSkipVerify turns off certificate verification, so any
+// certificate and host name are accepted. This makes the connection open to
+// man-in-the-middle attacks; only use it for testing against services with
+// self-signed certificates.
+func (rg *RushGo) WithInsecureSkipVerify() error {
+	return rg.configureTLS(func(cfg *tls.Config) *tls.Config {
+		cfg.InsecureSkipVerify = true
+		return cfg
+	})
+}
+
+// configureTLS hands a copy of the transport's TLS config (empty if unset) to
+// update and installs the result. The HTTP/2 transport is cloned like
+// installProxy does; the HTTP/3 round tripper caches a client per host with
+// the config it was created with, so those are closed.
+func (rg *RushGo) configureTLS(update func(*tls.Config) *tls.Config) error {
+	switch transport := rg.client.Transport.(type) {
+	case *http.Transport:
+		clone := transport.Clone()
+		if clone.TLSClientConfig == nil {
+			clone.TLSClientConfig = &tls.Config{}
+		}
+		clone.TLSClientConfig = update(clone.TLSClientConfig)
+		transport.CloseIdleConnections()
+		rg.client.Transport = clone
+		return nil
+	case *http3.RoundTripper:
+		cfg := &tls.Config{}
+		if transport.TLSClientConfig != nil {
+			cfg = transport.TLSClientConfig.Clone()
+		}
+		transport.TLSClientConfig = update(cfg)
+		return transport.Close()
+	default:
+		return fmt.Errorf("failed to set TLS config: not supported with custom transport %T", transport)
+	}
+}
+
+// transportTLSConfig returns a copy of the transport's TLS config, or an empty
+// one if it has none, for connections RushGo makes outside the transport
+func (rg *RushGo) transportTLSConfig() *tls.Config {
+	var cfg *tls.Config
+	switch transport := rg.client.Transport.(type) {
+	case *http.Transport:
+		cfg = transport.TLSClientConfig
+	case *http3.RoundTripper:
+		cfg = transport.TLSClientConfig
+	}
+	if cfg == nil {
+		return &tls.Config{}
+	}
+	return cfg.Clone()
+}
+
+// WithClientCert loads a PEM certificate and private key and presents them to
+// servers that ask for a client certificate (mutual TLS). It is added to any
+// certificates already configured and keeps the root CAs as they are. It
+// returns an error if the files can't be read or don't form a valid pair.
+func (rg *RushGo) WithClientCert(certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	return rg.configureTLS(func(cfg *tls.Config) *tls.Config {
+		cfg.Certificates = append(cfg.Certificates, cert)
+		return cfg
+	})
+}
+
+// WithRootCAs trusts the PEM-encoded CA certificates in pemData on top of the
+// system roots, so servers signed by an internal CA verify without turning
+// verification off. Calling it again adds more. It returns an error if
+// pemData holds no valid certificate.
+func (rg *RushGo) WithRootCAs(pemData []byte) error {
+	probe := x509.NewCertPool()
+	if !probe.AppendCertsFromPEM(pemData) {
+		return errors.New("failed to add root CAs: no valid PEM certificate found")
+	}
+	return rg.configureTLS(func(cfg *tls.Config) *tls.Config {
+		pool := cfg.RootCAs
+		if pool != nil {
+			pool = pool.Clone()
+		} else if pool, _ = x509.SystemCertPool(); pool == nil {
+			pool = x509.NewCertPool()
+		}
+		pool.AppendCertsFromPEM(pemData)
+		cfg.RootCAs = pool
+		return cfg
+	})
+}
+
+// WithRootCAFile is WithRootCAs with the certificates read from a PEM file
+func (rg *RushGo) WithRootCAFile(path string) error {
+	pemData, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to add root CAs: %w", err)
+	}
+	return rg.WithRootCAs(pemData)
+}
@@ -0,0 +1,98 @@
+package rushgo
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+// WithTransportConfig hands the underlying *http.Transport to configure for
+// any setting RushGo has no dedicated option for. It returns an error when the
+// client uses the HTTP/3 round tripper or a custom transport.
+func (rg *RushGo) WithTransportConfig(configure func(*http.Transport)) error {
+	switch transport := rg.client.Transport.(type) {
+	case *http.Transport:
+		configure(transport)
+		return nil
+	case *http3.RoundTripper:
+		return fmt.Errorf("transport config is not supported with HTTP/3")
+	default:
+		return fmt.Errorf("transport config is not supported with custom transport %T", transport)
+	}
+}
+
+// ProbeProtocol sends a HEAD request to url and reports the highest HTTP
+// version the server negotiates, as "HTTP/3.0", "HTTP/2.0" or "HTTP/1.1",
+// to help decide whether to enable HTTP/3 in Config. HTTP/3 is tried first for
+// https URLs, unless a proxy is set since HTTP/3 can't go through one. The
+// probes are built from the client's transport, so its proxy, dialer and TLS
+// settings (root CAs, client certificates, InsecureSkipVerify) apply, but use
+// their own connections; the client's are untouched. A custom transport is
+// probed as it is.
+func (rg *RushGo) ProbeProtocol(url string) (string, error) {
+	probes, err := rg.probeTransports(strings.HasPrefix(strings.ToLower(url), "https://"))
+	if err != nil {
+		return "", err
+	}
+
+	var lastErr error
+	for _, transport := range probes {
+		req, err := rg.newRequest("HEAD", url, nil, &requestOptions{})
+		if err != nil {
+			return "", err
+		}
+
+		client := &http.Client{Transport: transport, Timeout: rg.client.Timeout}
+		resp, err := client.Do(req)
+		if transport != rg.client.Transport {
+			closeProbe(transport)
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		resp.Body.Close()
+		return resp.Proto, nil
+	}
+	return "", fmt.Errorf("failed to probe %s: %w", url, lastErr)
+}
+
+// probeTransports returns the transports ProbeProtocol tries in turn, copies of
+// the client's with HTTP/2 allowed and, for https, an HTTP/3 one with the same
+// TLS config first
+func (rg *RushGo) probeTransports(https bool) ([]http.RoundTripper, error) {
+	h3 := &http3.RoundTripper{TLSClientConfig: rg.transportTLSConfig()}
+	var h2 *http.Transport
+	switch transport := rg.client.Transport.(type) {
+	case *http.Transport:
+		h2 = transport.Clone()
+		h2.TLSNextProto = nil // an empty map would turn HTTP/2 off
+	case *http3.RoundTripper:
+		h2 = &http.Transport{}
+		if rg.dialer != nil {
+			h2.DialContext = rg.dialer.DialContext
+		}
+		h3.QuicConfig = transport.QuicConfig
+	default:
+		return []http.RoundTripper{transport}, nil
+	}
+	h2.ForceAttemptHTTP2 = true
+	h2.TLSClientConfig = rg.transportTLSConfig()
+
+	if https && rg.proxyURL == nil && rg.proxyPool == nil {
+		return []http.RoundTripper{h3, h2}, nil
+	}
+	return []http.RoundTripper{h2}, nil
+}
+
+// closeProbe releases the connections of a probe transport
+func closeProbe(transport http.RoundTripper) {
+	switch transport := transport.(type) {
+	case *http.Transport:
+		transport.CloseIdleConnections()
+	case *http3.RoundTripper:
+		transport.Close()
+	}
+}
@@ -0,0 +1,32 @@
+package rushgo
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProbeProtocolUsesClientTLSConfig(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	// The test server's certificate is only trusted through WithRootCAs. The
+	// short timeout keeps the HTTP/3 attempt, which nothing answers, quick.
+	rg := New(&Config{EnableHTTP2: true, Timeout: time.Second})
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := rg.WithRootCAs(caPEM); err != nil {
+		t.Fatalf("WithRootCAs: %v", err)
+	}
+
+	proto, err := rg.ProbeProtocol(srv.URL)
+	if err != nil {
+		t.Fatalf("ProbeProtocol: %v", err)
+	}
+	if proto != "HTTP/2.0" {
+		t.Errorf("protocol = %q, want HTTP/2.0", proto)
+	}
+}
@@ -0,0 +1,89 @@
+package rushgo
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync/atomic"
+)
+
+// PostFile uploads the file at filePath as the raw request body. When
+// contentType is empty it is sniffed from the start of the file. The file is
+// streamed rather than read into memory and is closed after the request.
+func (rg *RushGo) PostFile(url, filePath string, contentType string) (*http.Response, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("failed to open file %s: is a directory", filePath)
+	}
+
+	if contentType == "" {
+		contentType, err = sniffFileContentType(filePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	size := info.Size()
+	return rg.do("POST", url, nil, &requestOptions{
+		headers: map[string]string{"Content-Type": contentType},
+		getBody: func() (io.ReadCloser, error) {
+			if size == 0 {
+				// A zero ContentLength with a real body reads as unknown and
+				// would be sent chunked, so an empty file sends no body
+				return http.NoBody, nil
+			}
+			file, err := os.Open(filePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open file %s: %w", filePath, err)
+			}
+			return file, nil
+		},
+		contentLength: size,
+	})
+}
+
+// PostCounted makes a POST request like Post and also returns the number of
+// body bytes that were actually sent
+func (rg *RushGo) PostCounted(url string, body []byte) (*http.Response, int64, error) {
+	var sent int64
+	resp, err := rg.do("POST", url, body, &requestOptions{sent: &sent})
+	return resp, atomic.LoadInt64(&sent), err
+}
+
+// sniffFileContentType detects the content type from the first 512 bytes of a file
+func sniffFileContentType(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", fmt.Errorf("failed to read file %s: %w", filePath, err)
+	}
+	return http.DetectContentType(buf[:n]), nil
+}
+
+// newStreamingRequest builds a request whose body comes from getBody, keeping
+// getBody around so the transport can resend it on redirects
+func newStreamingRequest(method, url string, getBody func() (io.ReadCloser, error), contentLength int64) (*http.Request, error) {
+	body, err := getBody()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+	req.ContentLength = contentLength
+	req.GetBody = getBody
+	return req, nil
+}
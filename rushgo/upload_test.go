@@ -0,0 +1,56 @@
+package rushgo
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPostFileContentLength(t *testing.T) {
+	type received struct {
+		contentLength    int64
+		transferEncoding []string
+		body             string
+	}
+	got := make(chan received, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- received{r.ContentLength, r.TransferEncoding, string(body)}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty", ""},
+		{"small", "hello"},
+	}
+	rg := New(nil)
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), tt.name)
+		if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := rg.PostFile(srv.URL, path, "text/plain")
+		if err != nil {
+			t.Fatalf("%s: PostFile: %v", tt.name, err)
+		}
+		resp.Body.Close()
+
+		r := <-got
+		if r.contentLength != int64(len(tt.content)) {
+			t.Errorf("%s: Content-Length = %d, want %d", tt.name, r.contentLength, len(tt.content))
+		}
+		if len(r.transferEncoding) > 0 {
+			t.Errorf("%s: Transfer-Encoding = %s, want none", tt.name, strings.Join(r.transferEncoding, ", "))
+		}
+		if r.body != tt.content {
+			t.Errorf("%s: body = %q, want %q", tt.name, r.body, tt.content)
+		}
+	}
+}
@@ -1,7 +1,9 @@
 package rushgo
 
 import (
+	"fmt"
 	"math/rand"
+	"strings"
 )
 
 type UserAgent string
@@ -45,3 +47,104 @@ func RandUserAgent() UserAgent {
 
 	return userAgents[rand.Intn(len(userAgents))]
 }
+
+// WithUserAgentPool replaces the built-in User-Agent list that
+// WithUserAgent("random") picks from. If a random User-Agent is already set,
+// a new one is picked from the pool. It returns an error for an empty pool.
+func (rg *RushGo) WithUserAgentPool(agents []string) error {
+	pool := make([]string, 0, len(agents))
+	for _, agent := range agents {
+		if strings.TrimSpace(agent) != "" {
+			pool = append(pool, agent)
+		}
+	}
+	if len(pool) == 0 {
+		return fmt.Errorf("user agent pool must not be empty")
+	}
+
+	rg.headersMu.Lock()
+	defer rg.headersMu.Unlock()
+	rg.userAgentPool = pool
+	if rg.userAgentRandom {
+		rg.userAgent = rg.randomUserAgent()
+	}
+	return nil
+}
+
+// randomUserAgent picks from the custom pool if one is set, otherwise from the
+// built-in list. The caller holds headersMu.
+func (rg *RushGo) randomUserAgent() string {
+	if len(rg.userAgentPool) > 0 {
+		return rg.userAgentPool[rand.Intn(len(rg.userAgentPool))]
+	}
+	return RandUserAgent().String()
+}
+
+// browserPresets holds internally consistent default headers for real browsers
+var browserPresets = map[string]map[string]string{
+	"chrome": {
+		"User-Agent":                "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+		"Accept":                    "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7",
+		"Accept-Language":           "en-US,en;q=0.9",
+		"Accept-Encoding":           "gzip, deflate",
+		"Sec-Ch-Ua":                 `"Not_A Brand";v="8", "Chromium";v="120", "Google Chrome";v="120"`,
+		"Sec-Ch-Ua-Mobile":          "?0",
+		"Sec-Ch-Ua-Platform":        `"Windows"`,
+		"Sec-Fetch-Dest":            "document",
+		"Sec-Fetch-Mode":            "navigate",
+		"Sec-Fetch-Site":            "none",
+		"Sec-Fetch-User":            "?1",
+		"Upgrade-Insecure-Requests": "1",
+	},
+	"firefox": {
+		"User-Agent":                "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:121.0) Gecko/20100101 Firefox/121.0",
+		"Accept":                    "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8",
+		"Accept-Language":           "en-US,en;q=0.5",
+		"Accept-Encoding":           "gzip, deflate",
+		"Sec-Fetch-Dest":            "document",
+		"Sec-Fetch-Mode":            "navigate",
+		"Sec-Fetch-Site":            "none",
+		"Sec-Fetch-User":            "?1",
+		"Upgrade-Insecure-Requests": "1",
+	},
+	"safari": {
+		"User-Agent":      "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Safari/605.1.15",
+		"Accept":          "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+		"Accept-Language": "en-US,en;q=0.9",
+		"Accept-Encoding": "gzip, deflate",
+		"Sec-Fetch-Dest":  "document",
+		"Sec-Fetch-Mode":  "navigate",
+		"Sec-Fetch-Site":  "none",
+	},
+}
+
+// BrowserPreset returns a copy of the headers WithBrowserPreset sends for
+// browser ("chrome", "firefox" or "safari"), and whether the preset exists
+func BrowserPreset(browser string) (map[string]string, bool) {
+	preset, ok := browserPresets[strings.ToLower(browser)]
+	if !ok {
+		return nil, false
+	}
+	headers := make(map[string]string, len(preset))
+	for key, value := range preset {
+		headers[key] = value
+	}
+	return headers, true
+}
+
+// WithBrowserPreset sets a realistic bundle of default headers, User-Agent
+// included, matching a real browser ("chrome", "firefox" or "safari"). An
+// unknown browser leaves the client unchanged. Because Accept-Encoding is set
+// explicitly, the transport does not decompress responses by itself; RushGo
+// decodes them instead. The presets leave br out of Accept-Encoding, since
+// brotli bodies can't be decoded and would reach the caller compressed.
+func (rg *RushGo) WithBrowserPreset(browser string) *RushGo {
+	headers, ok := BrowserPreset(browser)
+	if !ok {
+		return rg
+	}
+	rg.headersMu.Lock()
+	rg.userAgent = headers["User-Agent"]
+	rg.headersMu.Unlock()
+	return rg.WithHeaders(headers)
+}
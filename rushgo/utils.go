@@ -1,6 +1,12 @@
 package rushgo
 
 import (
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -9,3 +15,149 @@ func Second(seconds int) time.Duration {
     return time.Duration(seconds) * time.Second
 }
 
+// countingReader counts the bytes read through it. The count is updated
+// atomically since the transport may read a request body on its own goroutine.
+type countingReader struct {
+    r io.Reader
+    n *int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+    n, err := c.r.Read(p)
+    atomic.AddInt64(c.n, int64(n))
+    return n, err
+}
+
+// countingReadCloser is a countingReader that keeps the underlying Close
+type countingReadCloser struct {
+    countingReader
+    io.Closer
+}
+
+// expandPath replaces {name} placeholders in template with the path-escaped
+// params. Every placeholder needs a param and every param must be used.
+func expandPath(template string, params map[string]string) (string, error) {
+    var out strings.Builder
+    used := make(map[string]bool, len(params))
+
+    rest := template
+    for {
+        start := strings.Index(rest, "{")
+        if start == -1 {
+            out.WriteString(rest)
+            break
+        }
+        end := strings.Index(rest[start:], "}")
+        if end == -1 {
+            return "", fmt.Errorf("unclosed placeholder in path template %q", template)
+        }
+        name := rest[start+1 : start+end]
+        value, ok := params[name]
+        if !ok {
+            return "", fmt.Errorf("no param for placeholder {%s} in path template %q", name, template)
+        }
+        used[name] = true
+
+        out.WriteString(rest[:start])
+        out.WriteString(url.PathEscape(value))
+        rest = rest[start+end+1:]
+    }
+
+    for name := range params {
+        if !used[name] {
+            return "", fmt.Errorf("param %q is not used in path template %q", name, template)
+        }
+    }
+    return out.String(), nil
+}
+
+// mergeQuery sets params on the query string of rawURL, replacing keys that
+// are already present and keeping the rest
+func mergeQuery(rawURL string, params map[string][]string) (string, error) {
+    u, err := url.Parse(rawURL)
+    if err != nil {
+        return "", fmt.Errorf("invalid URL %q: %w", rawURL, err)
+    }
+    query := u.Query()
+    for key, values := range params {
+        query[key] = append([]string(nil), values...)
+    }
+    u.RawQuery = query.Encode()
+    return u.String(), nil
+}
+
+// encodeForm encodes data as an application/x-www-form-urlencoded string
+func encodeForm(data map[string]string) string {
+    form := url.Values{}
+    for key, value := range data {
+        form.Set(key, value)
+    }
+    return form.Encode()
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys(m map[string]string) []string {
+    keys := make([]string, 0, len(m))
+    for key := range m {
+        keys = append(keys, key)
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+// deleteHeader removes key from a header map in any letter case, since maps
+// filled by WithHeaders keep the caller's spelling
+func deleteHeader(headers map[string]string, key string) {
+    for name := range headers {
+        if strings.EqualFold(name, key) {
+            delete(headers, name)
+        }
+    }
+}
+
+// defaultPorts are the ports normalizeURLKey drops for each scheme
+var defaultPorts = map[string]string{"http": "80", "https": "443", "ws": "80", "wss": "443"}
+
+// normalizeURLKey turns rawURL into a key for matching requests to the same
+// resource: the scheme and host are lowercased and a default port is dropped,
+// so "HTTP://Example.com:80/x" and "http://example.com/x" share a key. The
+// path and query are kept as they are. Only use it for keys, never for the
+// request itself. A URL that doesn't parse is returned unchanged.
+func normalizeURLKey(rawURL string) string {
+    u, err := url.Parse(rawURL)
+    if err != nil || u.Host == "" {
+        return rawURL
+    }
+    u.Scheme = strings.ToLower(u.Scheme)
+    host, port := strings.ToLower(u.Hostname()), u.Port()
+    if strings.Contains(host, ":") {
+        host = "[" + host + "]"
+    }
+    if port != "" && port != defaultPorts[u.Scheme] {
+        host += ":" + port
+    }
+    u.Host = host
+    return u.String()
+}
+
+// decodeForm parses a URL-encoded form into one of the types GetDecode allows
+func decodeForm(data []byte, out interface{}) error {
+    values, err := url.ParseQuery(string(data))
+    if err != nil {
+        return fmt.Errorf("failed to decode form response: %w", err)
+    }
+    switch out := out.(type) {
+    case *url.Values:
+        *out = values
+    case *map[string][]string:
+        *out = values
+    case *map[string]string:
+        *out = make(map[string]string, len(values))
+        for key := range values {
+            (*out)[key] = values.Get(key)
+        }
+    default:
+        return fmt.Errorf("failed to decode form response: cannot decode into %T", out)
+    }
+    return nil
+}
@@ -0,0 +1,188 @@
+package rushgo
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketOptions configures WebSocketConnectWithOptions
+type WebSocketOptions struct {
+	// Origin is sent as the Origin header, overriding any default. Many
+	// servers reject handshakes without one.
+	Origin string
+
+	// PingInterval, if set, sends a ping control frame this often to keep the
+	// connection alive, until a ping fails (e.g. once the connection is closed)
+	PingInterval time.Duration
+
+	// PingJitter spreads pings randomly by up to this fraction of
+	// PingInterval either way, so many connections don't ping in lockstep.
+	// Zero means the default of 0.1 (±10%); a negative value disables jitter.
+	// Values above 0.9 are capped.
+	PingJitter float64
+}
+
+// defaultPingJitter is the PingJitter used when none is set, maxPingJitter
+// keeps the interval from shrinking towards zero
+const (
+	defaultPingJitter = 0.1
+	maxPingJitter     = 0.9
+)
+
+// pingWriteTimeout bounds how long a single keepalive ping may take to write
+const pingWriteTimeout = 10 * time.Second
+
+// webSocketManagedHeaders are set by the handshake itself; sending them from
+// the defaults would make the dial fail with a duplicate header error
+var webSocketManagedHeaders = map[string]bool{
+	"Upgrade":                  true,
+	"Connection":               true,
+	"Sec-Websocket-Key":        true,
+	"Sec-Websocket-Version":    true,
+	"Sec-Websocket-Extensions": true,
+}
+
+// webSocketListHeaders hold comma-separated lists, which the handshake sends
+// one element per header line. Others, such as Cookie or the Sec-Ch-Ua client
+// hints, are sent joined as they are.
+var webSocketListHeaders = map[string]bool{
+	"Accept":                 true,
+	"Accept-Encoding":        true,
+	"Accept-Language":        true,
+	"Cache-Control":          true,
+	"Pragma":                 true,
+	"Sec-Websocket-Protocol": true,
+}
+
+// WebSocketConnectWithOptions opens a WebSocket connection like
+// WebSocketConnect. The handshake carries the default and GET method headers
+// and the User-Agent, minus the headers the handshake manages itself. It goes
+// through the client's proxy (http or socks5), dialer, TLS settings and cookie
+// jar, and is bound by the client timeout.
+func (rg *RushGo) WebSocketConnectWithOptions(urlStr string, opts WebSocketOptions) (*websocket.Conn, *http.Response, error) {
+	return rg.webSocketConnect(context.Background(), urlStr, opts)
+}
+
+// webSocketConnect dials the WebSocket handshake bound to ctx
+func (rg *RushGo) webSocketConnect(ctx context.Context, urlStr string, opts WebSocketOptions) (*websocket.Conn, *http.Response, error) {
+	headers := rg.webSocketHeaders(opts)
+	rg.addJarCookies(headers, urlStr)
+	conn, resp, err := rg.webSocketDialer().DialContext(ctx, urlStr, headers)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.PingInterval > 0 {
+		go keepAlive(conn, opts.PingInterval, opts.PingJitter)
+	}
+	return conn, resp, nil
+}
+
+// webSocketDialer builds a dialer from the client's transport settings
+func (rg *RushGo) webSocketDialer() *websocket.Dialer {
+	dialer := &websocket.Dialer{
+		TLSClientConfig:  rg.transportTLSConfig(),
+		HandshakeTimeout: rg.client.Timeout,
+		Jar:              rg.client.Jar,
+	}
+	if rg.dialer != nil {
+		dialer.NetDialContext = rg.dialer.DialContext
+	}
+	if transport, ok := rg.client.Transport.(*http.Transport); ok {
+		dialer.Proxy = transport.Proxy
+		if transport.DialContext != nil {
+			dialer.NetDialContext = transport.DialContext
+		}
+	}
+	return dialer
+}
+
+// addJarCookies appends the jar's cookies for urlStr to the Cookie header, as
+// http.Client does. The dialer adds them itself only when there is no Cookie
+// header, which would otherwise replace them.
+func (rg *RushGo) addJarCookies(headers http.Header, urlStr string) {
+	u, err := url.Parse(urlStr)
+	if rg.client.Jar == nil || err != nil || headers.Get("Cookie") == "" {
+		return
+	}
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	}
+	req := &http.Request{Header: headers}
+	for _, cookie := range rg.client.Jar.Cookies(u) {
+		req.AddCookie(cookie)
+	}
+}
+
+// keepAlive pings conn at a jittered interval until a ping fails
+func keepAlive(conn *websocket.Conn, interval time.Duration, jitter float64) {
+	if jitter == 0 {
+		jitter = defaultPingJitter
+	}
+	if jitter < 0 {
+		jitter = 0
+	}
+	if jitter > maxPingJitter {
+		jitter = maxPingJitter
+	}
+	for {
+		time.Sleep(jitteredInterval(interval, jitter))
+		if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingWriteTimeout)); err != nil {
+			return
+		}
+	}
+}
+
+// jitteredInterval returns interval moved randomly by up to jitter*interval
+// either way
+func jitteredInterval(interval time.Duration, jitter float64) time.Duration {
+	offset := (rand.Float64()*2 - 1) * jitter * float64(interval)
+	return interval + time.Duration(offset)
+}
+
+// webSocketHeaders builds the handshake headers. Each header is set once
+// under its canonical name, so keys differing only in case can't duplicate it,
+// and list headers are split into one line per element.
+func (rg *RushGo) webSocketHeaders(opts WebSocketOptions) http.Header {
+	headers := http.Header{}
+	set := func(key, value string) {
+		key = http.CanonicalHeaderKey(key)
+		if webSocketManagedHeaders[key] {
+			return
+		}
+		headers.Del(key)
+		if !webSocketListHeaders[key] {
+			headers.Set(key, value)
+			return
+		}
+		for _, element := range strings.Split(value, ",") {
+			if element = strings.TrimSpace(element); element != "" {
+				headers.Add(key, element)
+			}
+		}
+	}
+
+	rg.headersMu.RLock()
+	for key, value := range rg.defaultHeaders {
+		set(key, value)
+	}
+	for key, value := range rg.methodHeaders["GET"] {
+		set(key, value)
+	}
+	if rg.userAgent != "" {
+		set("User-Agent", rg.userAgent)
+	}
+	rg.headersMu.RUnlock()
+	if opts.Origin != "" {
+		set("Origin", opts.Origin)
+	}
+	return headers
+}
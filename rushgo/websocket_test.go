@@ -0,0 +1,84 @@
+package rushgo
+
+import (
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocketHandshakeUsesClientSettings(t *testing.T) {
+	handshake := make(chan http.Header, 1)
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handshake <- r.Header.Clone()
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conn.Close()
+	}))
+	defer srv.Close()
+
+	// A SOCKS5 proxy that counts the connections going through it
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	var proxied int32
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			atomic.AddInt32(&proxied, 1)
+			go serveSOCKS5(conn)
+		}
+	}()
+
+	// The server's certificate is only trusted through WithRootCAs
+	rg := New(nil).WithCookieJar().WithCookies(map[string]string{"b": "2", "a": "1"}).WithHeaders(map[string]string{
+		"Accept-Language": "en-US, en;q=0.9",
+		"Sec-Ch-Ua":       `"Chromium";v="120", "Google Chrome";v="120"`,
+	})
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := rg.WithRootCAs(caPEM); err != nil {
+		t.Fatalf("WithRootCAs: %v", err)
+	}
+	if err := rg.SetProxy("socks5://" + ln.Addr().String()); err != nil {
+		t.Fatalf("SetProxy: %v", err)
+	}
+	serverURL, _ := url.Parse(srv.URL)
+	rg.client.Jar.SetCookies(serverURL, []*http.Cookie{{Name: "session", Value: "s"}})
+
+	conn, _, err := rg.WebSocketConnect("wss://" + serverURL.Host)
+	if err != nil {
+		t.Fatalf("WebSocketConnect: %v", err)
+	}
+	conn.Close()
+
+	if got := atomic.LoadInt32(&proxied); got != 1 {
+		t.Errorf("proxy saw %d connections, want 1", got)
+	}
+	header := <-handshake
+	tests := []struct {
+		key  string
+		want []string
+	}{
+		{"Accept-Language", []string{"en-US", "en;q=0.9"}},
+		{"Sec-Ch-Ua", []string{`"Chromium";v="120", "Google Chrome";v="120"`}},
+		{"Cookie", []string{"a=1; b=2; session=s"}},
+	}
+	for _, tt := range tests {
+		if got := header.Values(tt.key); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}